@@ -0,0 +1,547 @@
+package root
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	archive "github.com/antonio-leitao/nau/cmd/archive"
+	wizard "github.com/antonio-leitao/nau/cmd/new"
+	open "github.com/antonio-leitao/nau/cmd/open"
+	lib "github.com/antonio-leitao/nau/lib"
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// an action that can be run on the selected project
+type action struct {
+	name    string
+	binding key.Binding
+	//asks before running
+	confirm bool
+	//can run when no project is selected
+	anywhere bool
+	//fields asked before running
+	prompt func(m *model, project lib.Project) []field
+	//options offered before running
+	choices func(m *model, project lib.Project) []string
+	//runs inside the program with the answers to the prompt or the chosen option
+	run func(m *model, project lib.Project, values []string) tea.Cmd
+	//runs once the program is gone, unless nau stays open and the action can run inside it
+	after func(config lib.Config, project lib.Project)
+	//runs on each marked project, outside of the program loop
+	batch func(config lib.Config, project lib.Project, values []string) error
+}
+
+// a field of the prompt of an action
+type field struct {
+	title string
+	value string
+	limit int
+}
+
+// sent when an action running inside the program is done
+type actionDoneMsg struct {
+	message string
+	err     error
+	path    string //project to select after reloading
+}
+
+// sent with the projects found after an action changed them
+type refreshMsg struct {
+	projects []lib.Project
+	path     string
+}
+
+// actions available from the grid, in the order they show on the help
+var actions []*action
+
+func init() {
+	actions = []*action{
+		{
+			name: "open",
+			binding: key.NewBinding(
+				key.WithKeys("o", "enter"),
+				key.WithHelp("o", "open"),
+			),
+			confirm: true,
+			run: func(m *model, project lib.Project, values []string) tea.Cmd {
+				cmd, err := open.Command(m.config, project, "")
+				if err != nil {
+					return done("", err, project.Path)
+				}
+				lib.RecordOpen(project.Path)
+				//the editor takes the terminal until it exits
+				return tea.ExecProcess(cmd, func(err error) tea.Msg {
+					return actionDoneMsg{err: err, path: project.Path}
+				})
+			},
+			after: func(config lib.Config, project lib.Project) {
+				open.Open(config, project, "")
+			},
+		},
+		{
+			name: "archive",
+			binding: key.NewBinding(
+				key.WithKeys("a", "delete"),
+				key.WithHelp("a", "archive"),
+			),
+			confirm: true,
+			run: func(m *model, project lib.Project, values []string) tea.Cmd {
+				config := m.config
+				return m.work("Archiving "+project.Display_Name, func() tea.Msg {
+					err := archive.ArchiveProject(project.Path, config.Archives_path)
+					return actionDoneMsg{message: "Archived " + project.Display_Name, err: err, path: project.Path}
+				})
+			},
+			after: func(config lib.Config, project lib.Project) {
+				archive.Archive(project.Path, config.Archives_path)
+			},
+			batch: func(config lib.Config, project lib.Project, values []string) error {
+				return archive.ArchiveProject(project.Path, config.Archives_path)
+			},
+		},
+		{
+			name: "new",
+			binding: key.NewBinding(
+				key.WithKeys("n"),
+				key.WithHelp("n", "new"),
+			),
+			anywhere: true,
+			run:      startWizard,
+		},
+		{
+			name: "rename",
+			binding: key.NewBinding(
+				key.WithKeys("R"),
+				key.WithHelp("R", "rename"),
+			),
+			prompt: func(m *model, project lib.Project) []field {
+				return m.nameFields(project.Display_Name, project.Code)
+			},
+			run: func(m *model, project lib.Project, values []string) tea.Cmd {
+				name, code := values[0], valueAt(values, 1)
+				if err := lib.CheckUnique(m.initial_projects, name, code, project.Path); err != nil {
+					return done("", err, "")
+				}
+				config := m.config
+				return func() tea.Msg {
+					path, err := lib.RenameProject(config, project, name, code)
+					return actionDoneMsg{message: "Renamed to " + lib.ShortPath(path), err: err, path: path}
+				}
+			},
+		},
+		{
+			name: "duplicate",
+			binding: key.NewBinding(
+				key.WithKeys("d"),
+				key.WithHelp("d", "duplicate"),
+			),
+			prompt: func(m *model, project lib.Project) []field {
+				name := project.Display_Name + " Copy"
+				return m.nameFields(name, lib.SuggestCode(name, m.config.Code_length, m.codes()))
+			},
+			run: func(m *model, project lib.Project, values []string) tea.Cmd {
+				name, code := values[0], valueAt(values, 1)
+				if err := lib.CheckUnique(m.initial_projects, name, code, ""); err != nil {
+					return done("", err, "")
+				}
+				return func() tea.Msg {
+					path, err := lib.DuplicateProject(m.config, project, name, code)
+					return actionDoneMsg{message: "Copied to " + lib.ShortPath(path), err: err, path: path}
+				}
+			},
+		},
+		{
+			name: "tag",
+			binding: key.NewBinding(
+				key.WithKeys("t"),
+				key.WithHelp("t", "tag"),
+			),
+			prompt: func(m *model, project lib.Project) []field {
+				return []field{{title: "Tags", limit: 100}}
+			},
+			run: func(m *model, project lib.Project, values []string) tea.Cmd {
+				err := lib.AddTags(project.Path, strings.Fields(values[0]))
+				return done("Tagged "+project.Display_Name, err, project.Path)
+			},
+			batch: func(config lib.Config, project lib.Project, values []string) error {
+				return lib.AddTags(project.Path, strings.Fields(values[0]))
+			},
+		},
+		{
+			name: "pin",
+			binding: key.NewBinding(
+				key.WithKeys("p"),
+				key.WithHelp("p", "pin/unpin"),
+			),
+			run: func(m *model, project lib.Project, values []string) tea.Cmd {
+				err := lib.SetPinned(project.Path, !project.Pinned)
+				message := "Pinned " + project.Display_Name
+				if project.Pinned {
+					message = "Unpinned " + project.Display_Name
+				}
+				return done(message, err, project.Path)
+			},
+		},
+		{
+			name: "shell",
+			binding: key.NewBinding(
+				key.WithKeys("s"),
+				key.WithHelp("s", "shell"),
+			),
+			run: func(m *model, project lib.Project, values []string) tea.Cmd {
+				shell := os.Getenv("SHELL")
+				if shell == "" {
+					shell = "/bin/sh"
+				}
+				cmd := exec.Command(shell)
+				cmd.Dir = project.Path
+				return tea.ExecProcess(cmd, func(err error) tea.Msg {
+					//the exit status of the shell is whatever ran last in it
+					if _, ok := err.(*exec.ExitError); ok {
+						err = nil
+					}
+					return actionDoneMsg{err: err}
+				})
+			},
+		},
+		{
+			name: "copy path",
+			binding: key.NewBinding(
+				key.WithKeys("c"),
+				key.WithHelp("c", "copy path"),
+			),
+			run: func(m *model, project lib.Project, values []string) tea.Cmd {
+				err := clipboard.WriteAll(project.Path)
+				return done("Copied "+lib.ShortPath(project.Path), err, "")
+			},
+		},
+		{
+			name: "move",
+			binding: key.NewBinding(
+				key.WithKeys("m"),
+				key.WithHelp("m", "change template"),
+			),
+			choices: func(m *model, project lib.Project) []string {
+				var templates []string
+				for template := range m.config.Templates {
+					if template != project.Lang {
+						templates = append(templates, template)
+					}
+				}
+				sort.Strings(templates)
+				return templates
+			},
+			run: func(m *model, project lib.Project, values []string) tea.Cmd {
+				config := m.config
+				return func() tea.Msg {
+					path, err := lib.MoveProject(config, project, values[0])
+					return actionDoneMsg{message: "Moved to " + lib.ShortPath(path), err: err, path: path}
+				}
+			},
+			batch: func(config lib.Config, project lib.Project, values []string) error {
+				_, err := lib.MoveProject(config, project, values[0])
+				return err
+			},
+		},
+	}
+}
+
+func actionBindings() []key.Binding {
+	var bindings []key.Binding
+	for _, a := range actions {
+		bindings = append(bindings, a.binding)
+	}
+	return bindings
+}
+
+func done(message string, err error, path string) tea.Cmd {
+	return func() tea.Msg {
+		return actionDoneMsg{message: message, err: err, path: path}
+	}
+}
+
+func valueAt(values []string, i int) string {
+	if i < len(values) {
+		return strings.TrimSpace(values[i])
+	}
+	return ""
+}
+
+// name and code fields, the code only when projects have one
+func (m *model) nameFields(name string, code string) []field {
+	fields := []field{{title: "Name", value: name, limit: 40}}
+	if m.config.Code_length > 0 {
+		fields = append(fields, field{title: "Code", value: code, limit: m.config.Code_length})
+	}
+	return fields
+}
+
+func (m *model) codes() []string {
+	var codes []string
+	for _, project := range m.initial_projects {
+		codes = append(codes, project.Code)
+	}
+	return codes
+}
+
+func startWizard(m *model, project lib.Project, values []string) tea.Cmd {
+	w, err := wizard.NewEmbedded(m.config)
+	if err != nil {
+		return done("", err, "")
+	}
+	m.wizard = w
+	m.state = "new"
+	//the wizard needs to know the size of the terminal
+	size := tea.WindowSizeMsg{Width: m.termWidth, Height: m.termHeight}
+	return tea.Batch(w.Init(), func() tea.Msg { return size })
+}
+
+// startAction asks what it needs to ask and runs the action on the selected project
+func (m *model) startAction(a *action) tea.Cmd {
+	if targetProject.Archived && !a.anywhere {
+		m.message = "Archived projects cannot be changed"
+		return nil
+	}
+	targetAction = a
+	m.message = ""
+	m.actionErr = ""
+	//marked projects take the place of the selected one
+	m.batching = a.batch != nil && len(m.marked) > 0
+	switch {
+	case m.batching && a.prompt == nil && a.choices == nil:
+		return m.startBatch(nil)
+	case a.confirm:
+		confirmation = false
+		m.state = "confirm"
+	case a.prompt != nil:
+		m.inputs = nil
+		for i, f := range a.prompt(m, targetProject) {
+			input := textinput.New()
+			input.Prompt = f.title + ": "
+			input.SetValue(f.value)
+			input.CharLimit = f.limit
+			if i == 0 {
+				input.Focus()
+			}
+			m.inputs = append(m.inputs, input)
+		}
+		m.focus = 0
+		m.state = "prompt"
+	case a.choices != nil:
+		project := targetProject
+		if m.batching {
+			//marked projects can be of any template
+			project = lib.Project{}
+		}
+		m.options = a.choices(m, project)
+		if len(m.options) == 0 {
+			m.message = "Nothing to choose from"
+			return nil
+		}
+		m.choice = 0
+		m.state = "choose"
+	default:
+		return a.run(m, targetProject, nil)
+	}
+	return nil
+}
+
+// finishAction runs the confirmed action, inside the program if it can
+func (m *model) finishAction(values []string) tea.Cmd {
+	if m.batching {
+		return m.startBatch(values)
+	}
+	if targetAction.run == nil || (targetAction.after != nil && !m.config.Stay_open) {
+		m.quitting = true
+		return tea.Quit
+	}
+	//it runs here, there is nothing left for after the program
+	confirmation = false
+	return targetAction.run(m, targetProject, values)
+}
+
+// work shows a spinner with what is going on until cmd is done
+func (m *model) work(doing string, cmd tea.Cmd) tea.Cmd {
+	m.doing = doing
+	m.state = "working"
+	return tea.Batch(m.spinner.Tick, cmd)
+}
+
+func (m model) workingView() string {
+	return m.styles.PromptStyle.Render(m.spinner.View() + " " + m.doing + ellipsis)
+}
+
+// back returns to the grid once an action is done or cancelled
+func (m *model) back() {
+	if len(m.projects) == 0 {
+		m.state = "stasis"
+	} else {
+		m.state = "browsing"
+	}
+}
+
+func (m *model) handleActionDone(msg actionDoneMsg) tea.Cmd {
+	if msg.err != nil {
+		//keep the prompt open to fix the values
+		m.actionErr = msg.err.Error()
+		if m.state != "prompt" {
+			m.back()
+			m.message = m.actionErr
+		}
+		return nil
+	}
+	m.back()
+	m.message = msg.message
+	if msg.path == "" {
+		return nil
+	}
+	return m.refresh(msg.path)
+}
+
+// refresh finds the projects again and selects the one at path
+func (m model) refresh(path string) tea.Cmd {
+	config := m.config
+	return func() tea.Msg {
+		projects, err := lib.Reindex(config)
+		if err != nil {
+			return actionDoneMsg{err: err}
+		}
+		return refreshMsg{projects: projects, path: path}
+	}
+}
+
+func (m *model) handleRefresh(msg refreshMsg) tea.Cmd {
+	m.initial_projects = Projects(msg.projects)
+	m.selectedProject = lib.Project{Path: msg.path}
+	//forget marks on projects that are gone
+	found := make(map[string]bool)
+	for _, project := range m.initial_projects {
+		found[project.Path] = true
+	}
+	for path := range m.marked {
+		if !found[path] {
+			delete(m.marked, path)
+		}
+	}
+	state := m.state
+	if state == "stasis" {
+		m.state = "browsing"
+	}
+	m.sortProjects()
+	//a finished batch stays on screen until dismissed
+	if state == "batch" {
+		m.state = state
+	}
+	return tea.Batch(fetchAllGitStatus(m.initial_projects), fetchSessions(m.config.Multiplexer))
+}
+
+func (m *model) handleWizard(msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(wizard.DoneMsg); ok {
+		m.back()
+		if msg.Path == "" && msg.Err == nil {
+			return nil
+		}
+		return m.handleActionDone(actionDoneMsg{
+			message: "Created " + lib.ShortPath(msg.Path),
+			err:     msg.Err,
+			path:    msg.Path,
+		})
+	}
+	w, cmd := m.wizard.Update(msg)
+	m.wizard = w.(wizard.Model)
+	return cmd
+}
+
+func (m *model) handlePrompt(msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, m.keys.Cancel):
+			m.back()
+			return nil
+		case key.Matches(msg, m.keys.Enter):
+			if m.focus < len(m.inputs)-1 {
+				m.focusInput(m.focus + 1)
+				return nil
+			}
+			var values []string
+			for _, input := range m.inputs {
+				values = append(values, strings.TrimSpace(input.Value()))
+			}
+			m.actionErr = ""
+			return m.finishAction(values)
+		case key.Matches(msg, m.keys.NextField):
+			m.focusInput((m.focus + 1) % len(m.inputs))
+			return nil
+		}
+	}
+	var cmd tea.Cmd
+	m.inputs[m.focus], cmd = m.inputs[m.focus].Update(msg)
+	return cmd
+}
+
+func (m *model) focusInput(i int) {
+	m.inputs[m.focus].Blur()
+	m.focus = i
+	m.inputs[m.focus].Focus()
+}
+
+func (m *model) handleChoice(msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, m.keys.Cancel), key.Matches(msg, m.keys.Quit):
+			m.back()
+		case key.Matches(msg, m.keys.Up):
+			if m.choice > 0 {
+				m.choice--
+			}
+		case key.Matches(msg, m.keys.Down):
+			if m.choice < len(m.options)-1 {
+				m.choice++
+			}
+		case key.Matches(msg, m.keys.Enter):
+			return m.finishAction([]string{m.options[m.choice]})
+		}
+	}
+	return nil
+}
+
+// name of what the action runs on, in titles
+func (m model) actionSubject() string {
+	if m.batching {
+		return fmt.Sprintf("%d projects", len(m.marked))
+	}
+	return targetProject.Display_Name
+}
+
+func (m model) promptView() string {
+	title := fmt.Sprintf("%s %s", strings.Title(targetAction.name), m.actionSubject())
+	sections := []string{m.styles.PromptStyle.Render(title)}
+	for _, input := range m.inputs {
+		sections = append(sections, input.View())
+	}
+	if m.actionErr != "" {
+		sections = append(sections, "", m.styles.BlurredStyle.Render(m.actionErr))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
+
+func (m model) choiceView() string {
+	title := fmt.Sprintf("%s %s", strings.Title(targetAction.name), m.actionSubject())
+	sections := []string{m.styles.PromptStyle.Render(title)}
+	for i, option := range m.options {
+		if i == m.choice {
+			color := m.config.Templates[option]
+			sections = append(sections, lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render("> "+option))
+		} else {
+			sections = append(sections, m.styles.BlurredStyle.Render("  "+option))
+		}
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
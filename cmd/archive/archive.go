@@ -11,19 +11,10 @@ import (
 	"os/exec"
 	"path/filepath"
 
+	pick "github.com/antonio-leitao/nau/cmd/pick"
 	lib "github.com/antonio-leitao/nau/lib"
-	"github.com/sahilm/fuzzy"
 )
 
-type Projects []lib.Project
-
-func (p Projects) String(i int) string {
-	return p[i].Name
-}
-func (p Projects) Len() int {
-	return len(p)
-}
-
 func compressAndMove(srcDir string, destDir string) error {
 	// get the name of the source directory
 	srcDirName := filepath.Base(srcDir)
@@ -100,13 +91,9 @@ func compress(src string, buf io.Writer) error {
 }
 
 func runMakeArchive(targetDir string) error {
-	// Change to the target directory
-	err := os.Chdir(targetDir)
-	if err != nil {
-		return err
-	}
-	// Run the "make archive" command
+	// Run the "make archive" command in the target directory
 	cmd := exec.Command("make", "archive")
+	cmd.Dir = targetDir
 	_, _ = cmd.CombinedOutput()
     //here errors dont matter because we dont care if there are no makefiles
 
@@ -126,29 +113,36 @@ func archiveProject(destDir, srcDir string) error {
 	}
 	return nil
 }
-func Archive(projectPath string, archives_path string){
-    archivesPath, err := lib.ExpandPath(archives_path)
+// ArchiveProject archives a project and reports what went wrong instead of exiting
+func ArchiveProject(projectPath string, archives_path string) error {
+	archivesPath, err := lib.ExpandPath(archives_path)
 	if err != nil {
-		log.Printf("NAU ERROR: %s", err)
-		os.Exit(1)
+		return err
 	}
-	err = archiveProject(archivesPath, projectPath)
+	return archiveProject(archivesPath, projectPath)
+}
+func Archive(projectPath string, archives_path string){
+	err := ArchiveProject(projectPath, archives_path)
 	if err != nil {
 		log.Printf("NAU ERROR: %s", err)
 		os.Exit(1)
 	}
 
 }
-func Execute(config lib.Config, query string) {
+func Execute(config lib.Config, query string, yes bool) {
 	projectList, _ := lib.GetProjects(config)
-	projects := Projects(projectList)
-	candidates := fuzzy.FindFrom(query, projects)
-
+	project, err := pick.Resolve(projectList, query, "Archive")
+	if err == pick.ErrCancelled {
+		return
+	}
 	//exit it nothing is found
-	if len(candidates) == 0 {
-		fmt.Println("NAU ERROR: No project found")
+	if err != nil {
+		fmt.Printf("NAU ERROR: %s\n", err)
 		os.Exit(1)
 	}
-	projectPath := projects[candidates[0].Index].Path
-    Archive(projectPath,config.Archives_path)
+	//archiving deletes the project, make sure it is the right one
+	if !yes && !pick.Confirm(fmt.Sprintf("Archive %s (%s)?", project.Display_Name, lib.ShortPath(project.Path))) {
+		return
+	}
+    Archive(project.Path,config.Archives_path)
 }
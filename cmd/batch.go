@@ -0,0 +1,204 @@
+package root
+
+import (
+	"fmt"
+	"strings"
+
+	lib "github.com/antonio-leitao/nau/lib"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// a project of a batch and how it went
+type batchItem struct {
+	project lib.Project
+	status  string //waiting, running, done or failed
+	err     error
+}
+
+// an action applied to all the marked projects
+type batchRun struct {
+	action   *action
+	values   []string
+	items    []batchItem
+	started  bool
+	finished bool
+}
+
+// sent when the action is done with one project of the batch
+type batchItemMsg struct {
+	index int
+	err   error
+}
+
+func (m *model) toggleMark(project lib.Project) {
+	if project.Archived {
+		return
+	}
+	if m.marked[project.Path] {
+		delete(m.marked, project.Path)
+	} else {
+		m.marked[project.Path] = true
+	}
+	//marks make cells wider
+	m.updateGrid()
+}
+
+// markAll marks every project that passes the filter, or unmarks them if they all are
+func (m *model) markAll() {
+	all := true
+	for _, project := range m.projects {
+		if !project.Archived && !m.marked[project.Path] {
+			all = false
+		}
+	}
+	for _, project := range m.projects {
+		if project.Archived {
+			continue
+		}
+		if all {
+			delete(m.marked, project.Path)
+		} else {
+			m.marked[project.Path] = true
+		}
+	}
+	m.updateGrid()
+}
+
+func (m model) markedProjects() []lib.Project {
+	var projects []lib.Project
+	for _, project := range m.initial_projects {
+		if m.marked[project.Path] {
+			projects = append(projects, project)
+		}
+	}
+	return projects
+}
+
+// startBatch lists the marked projects and asks before running the action on them
+func (m *model) startBatch(values []string) tea.Cmd {
+	m.batch = batchRun{action: targetAction, values: values}
+	for _, project := range m.markedProjects() {
+		m.batch.items = append(m.batch.items, batchItem{project: project, status: "waiting"})
+	}
+	confirmation = false
+	m.state = "batch"
+	return nil
+}
+
+// runBatchItem runs the action on one project of the batch
+func (m *model) runBatchItem(index int) tea.Cmd {
+	config, batch := m.config, m.batch
+	m.batch.items[index].status = "running"
+	return func() tea.Msg {
+		err := batch.action.batch(config, batch.items[index].project, batch.values)
+		return batchItemMsg{index: index, err: err}
+	}
+}
+
+func (m *model) handleBatchItem(msg batchItemMsg) tea.Cmd {
+	item := &m.batch.items[msg.index]
+	item.err = msg.err
+	if msg.err != nil {
+		item.status = "failed"
+	} else {
+		item.status = "done"
+		delete(m.marked, item.project.Path)
+	}
+	if next := msg.index + 1; next < len(m.batch.items) {
+		return m.runBatchItem(next)
+	}
+	m.batch.finished = true
+	//the projects may have moved or be gone
+	return m.refresh(m.selectedProject.Path)
+}
+
+func (m *model) handleBatch(msg tea.Msg) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+	switch {
+	case m.batch.finished:
+		if key.Matches(keyMsg, m.keys.Enter, m.keys.Cancel, m.keys.Quit) {
+			m.back()
+			m.message = m.batchSummary()
+		}
+	case m.batch.started:
+		//nothing to do but wait
+	case key.Matches(keyMsg, m.keys.Toggle):
+		confirmation = !confirmation
+	case key.Matches(keyMsg, m.keys.Enter):
+		//the marked projects may all be gone by now
+		if !confirmation || len(m.batch.items) == 0 {
+			m.back()
+			return nil
+		}
+		m.batch.started = true
+		return tea.Batch(m.spinner.Tick, m.runBatchItem(0))
+	case key.Matches(keyMsg, m.keys.Cancel, m.keys.Quit):
+		m.back()
+	}
+	return nil
+}
+
+func (m model) batchSummary() string {
+	failed := 0
+	for _, item := range m.batch.items {
+		if item.status == "failed" {
+			failed++
+		}
+	}
+	summary := fmt.Sprintf("%s %d of %d projects", strings.Title(m.batch.action.name), len(m.batch.items)-failed, len(m.batch.items))
+	if failed > 0 {
+		summary += fmt.Sprintf(", %d failed", failed)
+	}
+	return summary
+}
+
+func (m model) batchView() string {
+	var title string
+	switch {
+	case m.batch.finished:
+		title = m.batchSummary()
+	case m.batch.started:
+		title = fmt.Sprintf("%s %d projects", strings.Title(m.batch.action.name), len(m.batch.items))
+	default:
+		title = fmt.Sprintf("%s %d projects?", strings.Title(m.batch.action.name), len(m.batch.items))
+	}
+	if len(m.batch.values) > 0 {
+		title += m.styles.DividerDot.String() + strings.Join(m.batch.values, " ")
+	}
+	sections := []string{m.styles.PromptStyle.Render(title)}
+	for _, item := range m.batch.items {
+		var line string
+		switch item.status {
+		case "running":
+			line = m.spinner.View() + " " + item.project.Display_Name
+		case "done":
+			line = "✓ " + item.project.Display_Name
+		case "failed":
+			line = "✗ " + item.project.Display_Name + m.styles.BlurredStyle.Render(" "+item.err.Error())
+		default:
+			line = m.styles.BlurredStyle.Render(bullet + " " + item.project.Display_Name)
+		}
+		sections = append(sections, line)
+	}
+	switch {
+	case m.batch.finished:
+		sections = append(sections, "", m.styles.BlurredStyle.Render("enter to go back"))
+	case !m.batch.started:
+		var aff, neg string
+		if confirmation {
+			aff = m.styles.SelectedStyle.Render("Yes")
+			neg = m.styles.UnselectedStyle.Render("No")
+		} else {
+			aff = m.styles.UnselectedStyle.Render("Yes")
+			neg = m.styles.SelectedStyle.Render("No")
+		}
+		sections = append(sections,
+			lipgloss.NewStyle().Margin(0, 0, 2, 0).Render(lipgloss.JoinHorizontal(lipgloss.Left, aff, neg)))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
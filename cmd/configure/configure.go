@@ -5,9 +5,11 @@ package configure
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"strings"
+	"text/tabwriter"
 
 	lib "github.com/antonio-leitao/nau/lib"
 	"github.com/charmbracelet/bubbles/help"
@@ -101,8 +103,10 @@ type Field struct {
 	input textinput.Model
 }
 type model struct {
+	title      string
 	focusIndex int
 	inputs     []Field
+	saved      []string //values in the file, only the fields that differ are written
 	keys       keyMap
 	help       help.Model
 	styles     Styles
@@ -112,9 +116,26 @@ type model struct {
 	width      int
 }
 
-func initialModel(base_color string) model {
+// fields that can stay empty, templates that use them refuse to render until they are set
+var identityFields = []string{"AUTHOR", "EMAIL", "REMOTE"}
+
+func isIdentityField(field string) bool {
+	for _, f := range identityFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// initialModel starts every field with its value in config. With saveAll every field is written
+// on submit, otherwise only the ones that were changed.
+func initialModel(config lib.Config, title string, saveAll bool) model {
+	base_color := config.Base_color
 	m := model{
+		title:      title,
 		inputs:     make([]Field, len(lib.CustomizableFields)),
+		saved:      make([]string, len(lib.CustomizableFields)),
 		keys:       keys,
 		help:       help.New(),
 		styles:     DefaultStyles(base_color),
@@ -133,6 +154,11 @@ func initialModel(base_color string) model {
 		f.name = field
 		f.input.Prompt = " • "
 		f.input.Placeholder = normalizeString(field)
+		value, _ := lib.FieldValue(config, field)
+		f.input.SetValue(value)
+		if !saveAll {
+			m.saved[i] = value
+		}
 		//focus on the first field right away
 		if i == 0 {
 			f.input.Focus()
@@ -175,8 +201,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				//Evaluate first and submit later
 				m.Validate()
 				//if there are not errors just go
-				if allStringsEmpty(m.errors) {
-					return m, m.Submit
+				if allStringsEmpty(m.errors) && m.Submit() {
+					return m, tea.Quit
 				}
 			}
 		}
@@ -239,7 +265,9 @@ func (m model) Validate() (tea.Model, tea.Cmd) {
 	for i, field := range lib.CustomizableFields {
 		value := m.inputs[i].input.Value()
 		if len(value) == 0 {
-			m.errors[i] = m.styles.warningStyle.Render("• Field cannot be empty")
+			if !isIdentityField(field) {
+				m.errors[i] = m.styles.warningStyle.Render("• Field cannot be empty")
+			}
 			continue
 		}
 		error_string := lib.ValidateValue(field, value)
@@ -250,11 +278,21 @@ func (m model) Validate() (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func (m model) Submit() tea.Msg {
-	for _, pair := range m.inputs {
-		lib.UpdateConfigField(pair.name, pair.input.Value())
+// Submit writes the fields that changed, the ones that could not be written show their error
+func (m model) Submit() bool {
+	ok := true
+	for i, pair := range m.inputs {
+		if pair.input.Value() == m.saved[i] {
+			continue
+		}
+		if err := lib.UpdateConfigField(pair.name, pair.input.Value()); err != nil {
+			m.errors[i] = m.styles.errorStyle.Render("• " + err.Error())
+			ok = false
+			continue
+		}
+		m.saved[i] = pair.input.Value()
 	}
-	return tea.Quit() //this will make program run ad infinitum. Change to tea.Quit()
+	return ok
 }
 func (m model) getLongestEntry() int {
 	max_len := 0 //minwidth
@@ -273,7 +311,7 @@ func (m model) getLongestEntry() int {
 func (m model) View() string {
 	var b strings.Builder
 	//Add header title
-	title := m.styles.titleStyle.Render("Configure NAU")
+	title := m.styles.titleStyle.Render(m.title)
 	b.WriteString(title)
 	b.WriteString("\n")
 	//get max_width
@@ -309,8 +347,8 @@ func (m model) View() string {
 		b.String(),
 	)
 }
-func init_config(base_color string) {
-	model := initialModel(base_color)
+func init_config(config lib.Config, title string, saveAll bool) {
+	model := initialModel(config, title, saveAll)
 	if _, err := tea.NewProgram(model, tea.WithAltScreen()).Run(); err != nil {
 		log.Printf("NAU ERROR could not start program: %s\n", err)
 		os.Exit(1)
@@ -318,12 +356,13 @@ func init_config(base_color string) {
 }
 func Execute(args []string) {
 	if len(args) == 0 {
-        config, err := lib.ReadConfig()
+        //overrides are for a single run, they are not written to the file
+        config, err := lib.ReadSavedConfig()
         if err != nil{
             log.Println("Could not load default config")
         }
 		// No arguments provided
-		init_config(config.Base_color)
+		init_config(config, "Configure NAU", false)
 	} else if len(args) == 1 {
         config, err := lib.LoadConfig()
         if err != nil{
@@ -334,6 +373,14 @@ nau config [field] [value] #to set individual ones
                 `)
         }
 		// Only field provided
+		if command, ok := config.LauncherField(args[0]); ok {
+			fmt.Println(command)
+			return
+		}
+		if layout, ok := config.SessionField(args[0]); ok {
+			fmt.Println(layout)
+			return
+		}
 		lib.OutputField(config, args[0])
 	} else if len(args) > 1 {
 		// Run program for field and value case
@@ -344,3 +391,50 @@ nau config [field] [value] #to set individual ones
 		}
 	}
 }
+
+// Onboard asks for the configuration the first time nau runs, starting from what git and the environment tell
+func Onboard() {
+	config, err := lib.ReadSavedConfig()
+	if err != nil {
+		log.Println("Could not load default config:", err)
+		return
+	}
+	init_config(config, "Welcome to NAU", true)
+	if !lib.ConfigExists() {
+		//asking on every run would get in the way, nau config is there to come back to it
+		lib.DismissOnboarding()
+		path, _ := lib.ConfigPath()
+		fmt.Fprintf(os.Stderr, "No configuration saved, nau uses the defaults until you run \"nau config\" or write %s\n", path)
+	}
+}
+
+// ShowOrigin prints the value of every field and where it was set
+func ShowOrigin() {
+	if err := showOrigin(os.Stdout); err != nil {
+		log.Println("Could not load config:", err)
+		os.Exit(1)
+	}
+}
+
+func showOrigin(out io.Writer) error {
+	config, err := lib.ReadConfig()
+	if err != nil {
+		return err
+	}
+	path, _ := lib.ConfigPath()
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, field := range lib.CustomizableFields {
+		value, _ := lib.FieldValue(config, field)
+		origin := config.Origins[field]
+		switch origin {
+		case lib.OriginFile:
+			origin += " " + path
+		case lib.OriginEnv:
+			origin += " NAU_" + field
+		case lib.OriginFlag:
+			origin += " --set"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", field, value, origin)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,86 @@
+package configure
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/antonio-leitao/nau/internal/testconfig"
+	lib "github.com/antonio-leitao/nau/lib"
+)
+
+func TestShowOrigin(t *testing.T) {
+	path := testconfig.Use(t, "base_color = \"#111111\"\n")
+	t.Setenv("NAU_CODE_LENGTH", "2")
+	lib.SetOverrides([]string{"STAY_OPEN=true"})
+	t.Cleanup(func() { lib.SetOverrides(nil) })
+	var out bytes.Buffer
+	if err := showOrigin(&out); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		"BASE_COLOR":     {"#111111", "file", path},
+		"CODE_LENGTH":    {"2", "env", "NAU_CODE_LENGTH"},
+		"STAY_OPEN":      {"true", "flag", "--set"},
+		"FOLDER_PATTERN": {lib.DefaultFolderPattern, "default"},
+	}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		fields := strings.Fields(line)
+		if expected, ok := want[fields[0]]; ok {
+			if strings.Join(fields[1:], " ") != strings.Join(expected, " ") {
+				t.Errorf("show origin of %s = %q, want %q", fields[0], fields[1:], expected)
+			}
+			delete(want, fields[0])
+		}
+	}
+	for field := range want {
+		t.Errorf("show origin has no line for %s", field)
+	}
+}
+
+// submit runs the form with the value of one field changed
+func submit(t *testing.T, field string, value string) (model, bool) {
+	config, err := lib.ReadSavedConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := initialModel(config, "", false)
+	for i := range m.inputs {
+		if m.inputs[i].name == field {
+			m.inputs[i].input.SetValue(value)
+		}
+	}
+	return m, m.Submit()
+}
+
+func TestSubmitWritesChangedFields(t *testing.T) {
+	path := testconfig.Use(t, "# mine\nbase_color = \"#111111\"\n")
+	t.Setenv("NAU_EDITOR", "true")
+	lib.SetOverrides([]string{"CODE_LENGTH=2"})
+	t.Cleanup(func() { lib.SetOverrides(nil) })
+	if _, ok := submit(t, "STAY_OPEN", "true"); !ok {
+		t.Fatal("Submit failed")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# mine\nbase_color = \"#111111\"\nstay_open = true\n"
+	if string(data) != want {
+		t.Errorf("config file =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestSubmitReportsErrors(t *testing.T) {
+	testconfig.Use(t, "[[roots]]\npath = \"/tmp\"\n")
+	m, ok := submit(t, "PROJECTS_PATH", "/var")
+	if ok {
+		t.Fatal("Submit changed PROJECTS_PATH next to [[roots]]")
+	}
+	for i, field := range lib.CustomizableFields {
+		if (m.errors[i] != "") != (field == "PROJECTS_PATH") {
+			t.Errorf("error of %s = %q", field, m.errors[i])
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package root
+
+import (
+	"strings"
+
+	lib "github.com/antonio-leitao/nau/lib"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/truncate"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+const (
+	paneWidth     = 44
+	minPaneWidth  = 100 //narrower terminals do not show the pane
+	logLines      = 5
+	readmeLines   = 4
+	paneTextWidth = paneWidth - 4
+)
+
+// information about a project that is only read when it is selected
+type projectDetails struct {
+	description string
+	readme      string
+	log         []string
+	size        int64
+	partial     bool //the size is of the part of the project that was measured
+	requested   bool
+	loaded      bool
+	sized       bool
+}
+
+type detailsMsg struct {
+	path        string
+	description string
+	readme      string
+	log         []string
+}
+
+type sizeMsg struct {
+	path     string
+	size     int64
+	complete bool
+}
+
+func fetchDetails(path string) tea.Cmd {
+	return func() tea.Msg {
+		meta, _ := lib.ReadMetadata(path)
+		return detailsMsg{
+			path:        path,
+			description: meta.Description,
+			readme:      lib.ReadmeParagraph(path),
+			log:         lib.GitLog(path, logLines),
+		}
+	}
+}
+
+// loadDetails requests the details of the selected project the first time it is selected
+func (m model) loadDetails() tea.Cmd {
+	if len(m.projects) == 0 || !m.showDetails() {
+		return nil
+	}
+	path := m.projects[m.cursor].Path
+	d := m.details[path]
+	if d.requested {
+		return nil
+	}
+	d.requested = true
+	m.details[path] = d
+	cmds := []tea.Cmd{fetchDetails(path)}
+	//walking the whole project can take a while so it comes on its own
+	if !m.sizing[path] {
+		m.sizing[path] = true
+		cmds = append(cmds, fetchSize(path))
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m model) updateDetails(msg tea.Msg) {
+	switch msg := msg.(type) {
+	case detailsMsg:
+		d := m.details[msg.path]
+		d.description = msg.description
+		d.readme = msg.readme
+		d.log = msg.log
+		d.loaded = true
+		m.details[msg.path] = d
+	case sizeMsg:
+		d := m.details[msg.path]
+		d.size = msg.size
+		d.partial = !msg.complete
+		d.sized = true
+		m.details[msg.path] = d
+	}
+}
+
+// sizeText formats the size, marking one that was only partly measured, or returns missing before it is known
+func (d projectDetails) sizeText(missing string) string {
+	if !d.sized {
+		return missing
+	}
+	if d.partial {
+		return "> " + lib.FormatSize(d.size)
+	}
+	return lib.FormatSize(d.size)
+}
+
+func (m model) showDetails() bool {
+	return m.termWidth >= minPaneWidth
+}
+
+func (m model) detailsView() string {
+	if len(m.projects) == 0 {
+		return ""
+	}
+	project := m.projects[m.cursor]
+	d := m.details[project.Path]
+	var lines []string
+	lines = append(lines, m.styles.PaneTitle.Foreground(lipgloss.Color(project.Color)).Render(project.Display_Name))
+	lines = append(lines, m.styles.BlurredStyle.Render(truncate.StringWithTail(lib.ShortPath(project.Path), paneTextWidth, ellipsis)))
+	//where it belongs
+	info := []string{project.Lang}
+	if project.Code != "" {
+		info = append(info, project.Code)
+	}
+	if m.showRoots {
+		info = append(info, project.Root)
+	}
+	if project.Group != "" {
+		info = append(info, project.Group)
+	}
+	lines = append(lines, "", strings.Join(info, " "+bullet+" "))
+	lines = append(lines, m.styles.BlurredStyle.Render("modified ")+lib.RelativeTime(project.Timestamp))
+	lines = append(lines, m.styles.BlurredStyle.Render("size ")+d.sizeText(ellipsis))
+	if len(project.Tags) > 0 {
+		tags := wordwrap.String(strings.Join(project.Tags, " "+bullet+" "), paneTextWidth-5)
+		lines = append(lines, m.styles.BlurredStyle.Render("tags ")+tags)
+	}
+	//what it is about
+	if d.description != "" {
+		lines = append(lines, "", wordwrap.String(d.description, paneTextWidth))
+	}
+	if d.readme != "" {
+		readme := strings.Split(wordwrap.String(d.readme, paneTextWidth), "\n")
+		if len(readme) > readmeLines {
+			readme = readme[:readmeLines]
+			readme[readmeLines-1] = truncate.StringWithTail(readme[readmeLines-1]+" ", paneTextWidth-1, "") + ellipsis
+		}
+		lines = append(lines, "", m.styles.BlurredStyle.Render(strings.Join(readme, "\n")))
+	}
+	//git
+	if status, ok := m.statuses[project.Path]; ok && status.IsRepo {
+		lines = append(lines, "", strings.Join(status.Summary(), " "+bullet+" "))
+		for _, line := range d.log {
+			lines = append(lines, m.styles.BlurredStyle.Render(truncate.StringWithTail(line, paneTextWidth, ellipsis)))
+		}
+	}
+	return m.styles.Pane.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
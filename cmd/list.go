@@ -0,0 +1,354 @@
+package root
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	lib "github.com/antonio-leitao/nau/lib"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/truncate"
+)
+
+// a column of the list view
+type column struct {
+	key   string
+	title string
+	width int //zero takes the space left
+}
+
+var columns = []column{
+	{key: "code", title: "Code", width: 8},
+	{key: "name", title: "Name"},
+	{key: "lang", title: "Language", width: 12},
+	{key: "modified", title: "Modified", width: 15},
+	{key: "size", title: "Size", width: 10},
+	{key: "git", title: "Git", width: 18},
+}
+
+// limits how many projects are measured at the same time
+var sizeSlots = make(chan struct{}, 4)
+
+func fetchSize(path string) tea.Cmd {
+	return func() tea.Msg {
+		sizeSlots <- struct{}{}
+		defer func() { <-sizeSlots }()
+		size, complete := lib.DiskSize(path)
+		return sizeMsg{path: path, size: size, complete: complete}
+	}
+}
+
+// loadSizes measures the projects on screen, or every project while they are sorted by size
+func (m model) loadSizes() tea.Cmd {
+	if m.view != "list" || len(m.projects) == 0 {
+		return nil
+	}
+	projects := m.projects
+	if m.sortBy != "size" {
+		first, last := m.visibleRange()
+		if first < 1 {
+			return nil
+		}
+		projects = m.projects[first-1 : last]
+	}
+	var cmds []tea.Cmd
+	for _, project := range projects {
+		if m.sizing[project.Path] {
+			continue
+		}
+		m.sizing[project.Path] = true
+		cmds = append(cmds, fetchSize(project.Path))
+	}
+	return tea.Batch(cmds...)
+}
+
+// orders dirty repositories first
+func gitRank(status lib.GitStatus) int {
+	switch {
+	case !status.IsRepo:
+		return 0
+	case status.Dirty():
+		return 3
+	case status.Ahead > 0 || status.Behind > 0:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// less compares two projects by the sort column, most recent, biggest and dirtiest first
+func (m model) less(a lib.Project, b lib.Project) bool {
+	switch m.sortBy {
+	case "code":
+		return a.Code < b.Code
+	case "name":
+		return strings.ToLower(a.Display_Name) < strings.ToLower(b.Display_Name)
+	case "lang":
+		return a.Lang < b.Lang
+	case "size":
+		return m.details[a.Path].size > m.details[b.Path].size
+	case "git":
+		return gitRank(m.statuses[a.Path]) > gitRank(m.statuses[b.Path])
+	default:
+		return a.Timestamp.After(b.Timestamp)
+	}
+}
+
+// sortProjects orders the projects keeping the cursor on the selected one
+func (m *model) sortProjects() {
+	sort.SliceStable(m.initial_projects, func(i, j int) bool {
+		return m.ordered(m.initial_projects[i], m.initial_projects[j])
+	})
+	selected := m.selectedProject.Path
+	m.applyFilter()
+	m.selectPath(selected)
+}
+
+// resortProjects orders the projects again once a size or a git status arrives, in place so
+// the scroll position stays and the cursor follows the selected project
+func (m *model) resortProjects() {
+	sort.SliceStable(m.initial_projects, func(i, j int) bool {
+		return m.ordered(m.initial_projects[i], m.initial_projects[j])
+	})
+	selected := m.selectedProject.Path
+	offset := m.offset
+	switch {
+	case m.query.Has("dirty"):
+		//the statuses decide which projects are shown
+		m.applyFilter()
+		m.offset = offset
+	case m.query.Text == "":
+		sort.SliceStable(m.projects, func(i, j int) bool {
+			return m.ordered(m.projects[i], m.projects[j])
+		})
+	}
+	//free text keeps the projects ordered by how well they match
+	m.selectPath(selected)
+	m.scrollToCursor()
+}
+
+// ordered compares two projects the way the grid and the list show them
+func (m model) ordered(a lib.Project, b lib.Project) bool {
+	if m.groupedList() && m.view == "list" && a.Lang != b.Lang {
+		return a.Lang < b.Lang
+	}
+	//pinned projects go first whatever the order
+	if a.Pinned != b.Pinned {
+		return a.Pinned
+	}
+	if m.reverse {
+		return m.less(b, a)
+	}
+	return m.less(a, b)
+}
+
+// selectPath moves the cursor to the project at path when it is shown
+func (m *model) selectPath(path string) {
+	for i, project := range m.projects {
+		if project.Path == path {
+			m.cursor = i
+			m.selectedProject = project
+			targetProject = project
+		}
+	}
+}
+
+func (m model) uiState() lib.UIState {
+	return lib.UIState{View: m.view, Sort: m.sortBy, Reverse: m.reverse, Grouped: m.grouped}
+}
+
+// a line of the list view, either a group header or a project
+type listLine struct {
+	lang  string
+	color string
+	count int
+	index int //-1 for headers
+}
+
+// groups are left out while free text orders the projects by how well they match
+func (m model) groupedList() bool {
+	return m.grouped && m.query.Text == ""
+}
+
+func (m model) listLines() []listLine {
+	var lines []listLine
+	header := -1
+	for i, project := range m.projects {
+		if m.groupedList() && (i == 0 || m.projects[i-1].Lang != project.Lang) {
+			lines = append(lines, listLine{lang: project.Lang, color: project.Color, index: -1})
+			header = len(lines) - 1
+		}
+		if header >= 0 {
+			lines[header].count++
+		}
+		lines = append(lines, listLine{index: i})
+	}
+	return lines
+}
+
+// line of the list where the cursor is
+func (m model) cursorLine(lines []listLine) int {
+	for i, line := range lines {
+		if line.index == m.cursor {
+			return i
+		}
+	}
+	return 0
+}
+
+func (m model) columnWidths() []int {
+	widths := make([]int, len(columns))
+	left := m.width
+	for i, c := range columns {
+		widths[i] = c.width
+		left -= c.width + 1
+	}
+	for i, c := range columns {
+		if c.width == 0 {
+			widths[i] = left
+			if widths[i] < 8 {
+				widths[i] = 8
+			}
+		}
+	}
+	return widths
+}
+
+func fitColumn(content string, width int, style lipgloss.Style) string {
+	if lipgloss.Width(content) > width {
+		content = truncate.StringWithTail(content, uint(width), ellipsis)
+	}
+	return style.Copy().Width(width).Render(content)
+}
+
+func (m model) headerRow() string {
+	widths := m.columnWidths()
+	var cells []string
+	for i, c := range columns {
+		title := c.title
+		if c.key == m.sortBy {
+			if m.reverse {
+				title += " ▲"
+			} else {
+				title += " ▼"
+			}
+		}
+		cells = append(cells, fitColumn(fmt.Sprintf("%d %s", i+1, title), widths[i], lipgloss.NewStyle()))
+	}
+	return m.styles.Count.Render(strings.Join(cells, " "))
+}
+
+func (m model) renderRow(index int) string {
+	project := m.projects[index]
+	widths := m.columnWidths()
+	size := m.details[project.Path].sizeText("")
+	base := lipgloss.NewStyle()
+	selected := false
+	switch {
+	case m.state == "filtering":
+		base = m.styles.BlurredStyle
+	case m.cursor == index:
+		title_text := "#ffffd7" //230
+		if !lib.IsSufficientContrast(title_text, project.Color) {
+			title_text = "235"
+		}
+		base = lipgloss.NewStyle().
+			Background(lipgloss.Color(project.Color)).
+			Foreground(lipgloss.Color(title_text))
+		selected = true
+	}
+	values := []string{
+		project.Code,
+		m.styledTitle(project, base, selected),
+		m.styledLang(project, base, selected),
+		lib.RelativeTime(project.Timestamp),
+		size,
+		strings.TrimSpace(m.gitBadge(project)),
+	}
+	var cells []string
+	for i, value := range values {
+		cells = append(cells, fitColumn(value, widths[i], base))
+	}
+	return strings.Join(cells, base.Render(" "))
+}
+
+func (m model) ListView() string {
+	lines := m.listLines()
+	rows := []string{m.headerRow()}
+	stop := m.offset + m.visibleRows() - 1
+	if stop > len(lines) {
+		stop = len(lines)
+	}
+	for _, line := range lines[m.offset:stop] {
+		if line.index < 0 {
+			header := fmt.Sprintf("%s (%d)", line.lang, line.count)
+			rows = append(rows, m.styles.PaneTitle.Foreground(lipgloss.Color(line.color)).Render(header))
+			continue
+		}
+		rows = append(rows, m.renderRow(line.index))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// scrollList keeps the line of the cursor, and its group header, on screen
+func (m *model) scrollList() {
+	lines := m.listLines()
+	visible := m.visibleRows() - 1
+	if visible < 1 {
+		visible = 1
+	}
+	line := m.cursorLine(lines)
+	top := line
+	if top > 0 && lines[top-1].index < 0 {
+		top--
+	}
+	if top < m.offset {
+		m.offset = top
+	}
+	if line >= m.offset+visible {
+		m.offset = line - visible + 1
+	}
+	if m.offset > len(lines)-visible {
+		m.offset = len(lines) - visible
+	}
+	if m.offset < 0 {
+		m.offset = 0
+	}
+}
+
+// first and last projects on screen
+func (m model) visibleRange() (int, int) {
+	if m.view == "list" {
+		lines := m.listLines()
+		stop := m.offset + m.visibleRows() - 1
+		if stop > len(lines) {
+			stop = len(lines)
+		}
+		first, last := -1, -1
+		for _, line := range lines[m.offset:stop] {
+			if line.index < 0 {
+				continue
+			}
+			if first < 0 {
+				first = line.index
+			}
+			last = line.index
+		}
+		return first + 1, last + 1
+	}
+	first := m.offset*m.numCols + 1
+	last := (m.offset + m.visibleRows()) * m.numCols
+	if last > len(m.projects) {
+		last = len(m.projects)
+	}
+	return first, last
+}
+
+// whether some projects are off screen
+func (m model) scrolls() bool {
+	if m.view == "list" {
+		return len(m.listLines()) > m.visibleRows()-1
+	}
+	return m.numRows() > m.visibleRows()
+}
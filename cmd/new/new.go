@@ -23,6 +23,12 @@ type Submission struct {
 	git          bool
 }
 
+// DoneMsg is sent instead of quitting when the wizard runs inside another program
+type DoneMsg struct {
+	Path string
+	Err  error
+}
+
 type KeyMap struct {
 	Next   key.Binding
 	Prev   key.Binding
@@ -87,6 +93,9 @@ type Model struct {
 	//existing projects
 	existing_codes []string
 	existing_names []string
+	//code input
+	code_length int
+	code_edited bool
 	//basic info
 	showHelp        bool
 	KeyMap          KeyMap
@@ -107,6 +116,10 @@ type Model struct {
 	confirmation bool
 	//really just to pass it along
 	config *lib.Config
+	//running inside another program
+	embedded bool
+	//how the wizard ended when it runs on its own
+	done DoneMsg
 	//adaptivsize
 	width  int
 	height int
@@ -128,9 +141,7 @@ func newModel(
 		Styles:          DefaultStyles(base_color),
 		Help:            help.New(),
 		index:           0,
-		inputs:          make([]textinput.Model, 2),
 		summary:         textarea.New(),
-		errors:          []string{"", ""},
 		status:          initial_status,
 		template:        template,
 		base_color:      base_color,
@@ -142,7 +153,15 @@ func newModel(
 		spinner:         spinner.New(),
 		confirmation:    true,
 		config:          config,
+		code_length:     config.Code_length,
+	}
+	//the code input is only there if projects have codes
+	if m.code_length > 0 {
+		m.inputs = make([]textinput.Model, 2)
+	} else {
+		m.inputs = make([]textinput.Model, 1)
 	}
+	m.errors = make([]string, len(m.inputs))
 
 	//style of the inputs
 	var t textinput.Model
@@ -158,8 +177,8 @@ func newModel(
 			t.PromptStyle = m.Styles.FocusedStyle
 			t.TextStyle = m.Styles.FocusedStyle
 		case 1:
-			t.Placeholder = "XXX"
-			t.CharLimit = 3
+			t.Placeholder = strings.Repeat("X", m.code_length)
+			t.CharLimit = m.code_length
 		}
 
 		m.inputs[i] = t
@@ -185,6 +204,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+	case DoneMsg:
+		//on its own the wizard keeps the result for Execute to report once the screen is back
+		if !m.embedded {
+			m.done = msg
+			return m, tea.Quit
+		}
 	}
 	switch m.status {
 	case "choose":
@@ -233,7 +258,7 @@ func (m Model) UpdateChoose(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 		case key.Matches(msg, m.KeyMap.Quit):
-			return m, tea.Quit
+			return m, m.quit(DoneMsg{})
 		case key.Matches(msg, m.KeyMap.ShowFullHelp):
 			fallthrough
 		case key.Matches(msg, m.KeyMap.CloseFullHelp):
@@ -266,7 +291,7 @@ func (m Model) UpdateInfo(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.status = "confirm"
 			}
 		case key.Matches(msg, m.KeyMap.Quit):
-			return m, tea.Quit
+			return m, m.quit(DoneMsg{})
 		case key.Matches(msg, m.KeyMap.ShowFullHelp):
 			fallthrough
 		case key.Matches(msg, m.KeyMap.CloseFullHelp):
@@ -292,7 +317,7 @@ func (m Model) UpdateConfirm(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.status = "waiting"
 			return m, m.Submit()
 		case key.Matches(msg, m.KeyMap.Quit):
-			return m, tea.Quit
+			return m, m.quit(DoneMsg{})
 		case key.Matches(msg, m.KeyMap.ShowFullHelp):
 			fallthrough
 		case key.Matches(msg, m.KeyMap.CloseFullHelp):
@@ -307,7 +332,7 @@ func (m Model) UpdateWaiting(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		switch {
 		case key.Matches(msg, m.KeyMap.Quit):
-			return m, tea.Quit
+			return m, m.quit(DoneMsg{})
 		case key.Matches(msg, m.KeyMap.ShowFullHelp):
 			fallthrough
 		case key.Matches(msg, m.KeyMap.CloseFullHelp):
@@ -380,7 +405,11 @@ func (m Model) ChooseView() string {
 func (m Model) InfoView() string {
 	var sections []string
 	//make styles here
-	sections = append(sections, m.Styles.Title.Render("Name and ID"))
+	if m.code_length > 0 {
+		sections = append(sections, m.Styles.Title.Render("Name and ID"))
+	} else {
+		sections = append(sections, m.Styles.Title.Render("Name"))
+	}
 	for i := range m.inputs {
 		sections = append(
 			sections,
@@ -451,38 +480,64 @@ func allStringsEmpty(strList []string) bool {
 
 func (m Model) Validate() {
 	//if empty conditions
-	m.errors[0] = ""
-	m.errors[1] = ""
+	for i := range m.errors {
+		m.errors[i] = ""
+	}
 	//validate name and code
 	name := lib.ToHyphenName(m.inputs[0].Value())
 	if contained(name, m.existing_names) {
 		m.errors[0] = m.Styles.ErrorStyle.Render("• Name already in use")
 	}
+	if len(m.inputs[0].Value()) == 0 {
+		m.errors[0] = m.Styles.WarningStyle.Render("• Name cannot be empty")
+	}
+	if m.code_length == 0 {
+		return
+	}
 	code := strings.ToUpper(m.inputs[1].Value())
 	if contained(code, m.existing_codes) {
 		m.errors[1] = m.Styles.ErrorStyle.Render("• Code already in use")
 	}
+	if len(code) < m.code_length {
+		m.errors[1] = m.Styles.WarningStyle.Render(fmt.Sprintf("• Code needs %d characters", m.code_length))
+	}
+}
 
-	if len(m.inputs[0].Value()) == 0 {
-		m.errors[0] = m.Styles.WarningStyle.Render("• Name cannot be empty")
+// suggests a code from the project name until the user writes one
+func (m *Model) suggestCode() {
+	if m.code_length == 0 || m.code_edited {
+		return
 	}
-	if len(m.inputs[1].Value()) < 3 {
-		m.errors[1] = m.Styles.WarningStyle.Render("• Code needs three letters")
+	suggestion := lib.SuggestCode(m.inputs[0].Value(), m.code_length, m.existing_codes)
+	if m.inputs[0].Value() == "" {
+		suggestion = ""
 	}
+	m.inputs[1].SetValue(suggestion)
 }
 
 func (m Model) Submit() tea.Cmd {
-	folder_name := lib.ToFolderName(m.inputs[0].Value())
-	code := strings.ToUpper(m.inputs[1].Value())
+	var code string
+	if m.code_length > 0 {
+		code = strings.ToUpper(m.inputs[1].Value())
+	}
+	folder_name, err := lib.FormatFolderName(m.config.Folder_pattern, code, m.inputs[0].Value())
+	if err != nil {
+		return m.quit(DoneMsg{Err: fmt.Errorf("Invalid FOLDER_PATTERN: %v", err)})
+	}
 	sub := Submission{
 		project_name: lib.ToDunderName(m.inputs[0].Value()),
-		folder_name:  code + "_" + folder_name,
+		folder_name:  folder_name,
 		repo_name:    lib.ToHyphenName(m.inputs[0].Value()),
 		description:  m.summary.Value(),
 		git:          m.confirmation,
 	}
-	createNewProject(sub, m.config, m.template)
-	return tea.Quit
+	path, err := createNewProject(sub, m.config, m.template)
+	return m.quit(DoneMsg{Path: path, Err: err})
+}
+
+// quit tells the program the wizard runs in that it is done, on its own the wizard then ends
+func (m Model) quit(done DoneMsg) tea.Cmd {
+	return func() tea.Msg { return done }
 }
 
 func (m Model) forceBounds() (tea.Model, tea.Cmd) {
@@ -520,13 +575,19 @@ func (m Model) forceBounds() (tea.Model, tea.Cmd) {
 }
 
 func (m *Model) updateInputs(msg tea.Msg) tea.Cmd {
-	m.Validate()
 	cmds := make([]tea.Cmd, len(m.inputs))
 	// Only text inputs with Focus() set will respond, so it's safe to simply
 	// update all of them here without any further logic.
 	for i := range m.inputs {
+		before := m.inputs[i].Value()
 		m.inputs[i], cmds[i] = m.inputs[i].Update(msg)
+		//once the user types a code stop suggesting one
+		if i == 1 && m.inputs[i].Focused() && before != m.inputs[i].Value() {
+			m.code_edited = m.inputs[i].Value() != ""
+		}
 	}
+	m.suggestCode()
+	m.Validate()
 
 	//update summary
 	var cmd tea.Cmd
@@ -612,14 +673,18 @@ func (m Model) ShortHelp() []key.Binding {
 	}
 }
 
-func Execute(config lib.Config, query string) {
-	//where do we start?
-	initial_state, template, base_color := HandleArgs(config, query)
+// NewEmbedded returns the wizard to run inside another program, starting from the choice of template
+func NewEmbedded(config lib.Config) (Model, error) {
+	m, err := newWizard(config, "choose", "", config.Base_color)
+	m.embedded = true
+	return m, err
+}
+
+func newWizard(config lib.Config, initial_state string, template string, base_color string) (Model, error) {
 	//get all projects names
 	projects, err := lib.GetProjects(config)
 	if err != nil {
-		log.Println(err)
-        os.Exit(1)
+		return Model{}, err
 	}
 	//separate them
 	var codes, repoNames []string
@@ -634,23 +699,35 @@ func Execute(config lib.Config, query string) {
 		templates = append(templates, lang)
 		template_colors = append(template_colors, color)
 	}
+	return newModel(
+		base_color,
+		initial_state,
+		template,
+		templates,
+		template_colors,
+		repoNames,
+		codes,
+		&config,
+	), nil
+}
 
+func Execute(config lib.Config, query string) {
+	//where do we start?
+	initial_state, template, base_color := HandleArgs(config, query)
+	m, err := newWizard(config, initial_state, template, base_color)
+	if err != nil {
+		log.Println(err)
+        os.Exit(1)
+	}
 	//start application
-	p := tea.NewProgram(
-		newModel(
-			base_color,
-			initial_state,
-			template,
-			templates,
-			template_colors,
-			repoNames,
-			codes,
-			&config,
-		),
-		tea.WithAltScreen(),
-	)
-	if _, err := p.Run(); err != nil {
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	final, err := p.Run()
+	if err != nil {
 		log.Println(err)
         os.Exit(1)
 	}
+	if done := final.(Model).done; done.Err != nil {
+		log.Printf("NAU error: %v", done.Err)
+		os.Exit(1)
+	}
 }
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"syscall"
 	"text/template"
+	"text/template/parse"
 
 	lib "github.com/antonio-leitao/nau/lib"
 	"github.com/sahilm/fuzzy"
@@ -25,28 +26,100 @@ type Data struct {
 	Description string
 }
 
-func loggit(msg string) error {
-	// Open the log file for writing, creating it if it doesn't exist
-	f, err := os.OpenFile("logger.txt", os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
-	if err != nil {
-		return err
+// fields of Data that come from the identity in the config, and the field that sets each
+var identityFields = map[string]string{"Author": "AUTHOR", "Email": "EMAIL", "Repo": "REMOTE"}
+
+func (d *Data) identity(name string) string {
+	switch name {
+	case "Author":
+		return d.Author
+	case "Email":
+		return d.Email
+	case "Repo":
+		return d.Repo
 	}
-	defer f.Close()
-	// Write the message to the log file
-	_, err = fmt.Fprintln(f, msg)
-	if err != nil {
-		return err
+	return ""
+}
+
+// checkIdentity refuses a template that uses an identity field that is not set, instead of leaving it blank
+func (d *Data) checkIdentity(tmpl *template.Template) error {
+	if tmpl.Tree == nil {
+		return nil
+	}
+	for _, name := range usedFields(tmpl.Tree.Root, nil) {
+		field, ok := identityFields[name]
+		if ok && d.identity(name) == "" {
+			return fmt.Errorf("%s uses {{.%s}} but %s is not set, set it with: nau config %s <value>",
+				filepath.Base(tmpl.Name()), name, field, strings.ToLower(field))
+		}
 	}
 	return nil
 }
 
-func createNewProject(sub Submission, config *lib.Config, template string) {
+// usedFields lists the fields of the data a template prints. Fields inside an if or a with that
+// tests them are left out, the template already handles them being unset.
+func usedFields(node parse.Node, guarded map[string]bool) []string {
+	var fields []string
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			fields = append(fields, usedFields(child, guarded)...)
+		}
+	case *parse.ActionNode:
+		fields = usedFields(n.Pipe, guarded)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, cmd := range n.Cmds {
+			fields = append(fields, usedFields(cmd, guarded)...)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			fields = append(fields, usedFields(arg, guarded)...)
+		}
+	case *parse.FieldNode:
+		if !guarded[n.Ident[0]] {
+			fields = append(fields, n.Ident[0])
+		}
+	case *parse.IfNode:
+		fields = append(usedFields(n.List, guard(guarded, n.Pipe)), usedFields(n.ElseList, guarded)...)
+	case *parse.WithNode:
+		fields = append(usedFields(n.List, guard(guarded, n.Pipe)), usedFields(n.ElseList, guarded)...)
+	case *parse.RangeNode:
+		fields = append(usedFields(n.Pipe, guarded), append(usedFields(n.List, guarded), usedFields(n.ElseList, guarded)...)...)
+	case *parse.TemplateNode:
+		fields = usedFields(n.Pipe, guarded)
+	}
+	return fields
+}
+
+// guard adds the fields the condition of an if or a with tests to the guarded ones
+func guard(guarded map[string]bool, condition *parse.PipeNode) map[string]bool {
+	inner := make(map[string]bool)
+	for field := range guarded {
+		inner[field] = true
+	}
+	for _, field := range usedFields(condition, nil) {
+		inner[field] = true
+	}
+	return inner
+}
+
+// createNewProject returns the path of the new project
+func createNewProject(sub Submission, config *lib.Config, template string) (string, error) {
+	path, err := config.NewProjectPath(template, sub.folder_name)
+	if err != nil {
+		return "", err
+	}
 	//if the project is empty just start an empty one
 	if template == "Empty" {
-		newEmptyProject(sub, config)
-	} else {
-		createTemplateProject(sub, config, template)
+		return path, newEmptyProject(sub, config)
 	}
+	return path, createTemplateProject(sub, config, template)
 }
 
 func createTemplateProject(sub Submission, config *lib.Config, template string)error {
@@ -54,7 +127,7 @@ func createTemplateProject(sub Submission, config *lib.Config, template string)e
 	data := Data{
 		Author:      config.Author,
 		Email:       config.Email,
-		Repo:        config.Remote + "/" + sub.repo_name,
+		Repo:        remoteRepo(config.Remote, sub.repo_name),
 		Git:         sub.git,
 		Name:        sub.project_name,
 		Description: sub.description,
@@ -66,45 +139,76 @@ func createTemplateProject(sub Submission, config *lib.Config, template string)e
     }
     source_path := templates_path + "/" + template + "_" + config.Templates[template]
 	//convert target_path
-	projects_path, err := lib.ExpandPath(config.Projects_path)
-    if err != nil{
-        return err
-    }
-    target_path := projects_path + "/" + template + "/" + sub.folder_name
-	//create new direcotry
-	err = os.MkdirAll(target_path, 0755)
+	target_path, err := config.NewProjectPath(template, sub.folder_name)
     if err != nil{
         return err
     }
+	//create new direcotry, a folder that is already there is not ours to fill or remove
+	if err := os.MkdirAll(filepath.Dir(target_path), 0755); err != nil {
+		return err
+	}
+	if err := os.Mkdir(target_path, 0755); err != nil {
+		if os.IsExist(err) {
+			return fmt.Errorf("%s already exists", lib.ShortPath(target_path))
+		}
+		return err
+	}
+	//a template that cannot be rendered leaves nothing behind
+	created := false
+	defer func() {
+		if !created {
+			os.RemoveAll(target_path)
+		}
+	}()
 	//place everything there
 	err = CopyDirectory(source_path, target_path, &data)
 	if err != nil {
-		loggit(err.Error())
-		log.Printf("NAU ERROR: Failed to create Template: %v", err)
 		return err
 	}
 	//colapse template
 	err = CollapseDirectory(target_path, &data)
 	if err != nil {
-		loggit(err.Error())
-		log.Println("NaError processing directory:", err)
 		return err
 	}
-    return nil
+	created = true
+	return saveDescription(target_path, sub.description)
+}
+
+// remoteRepo is the remote of a new repository, empty without a REMOTE so templates cannot use a broken one
+func remoteRepo(remote string, repo_name string) string {
+	if remote == "" {
+		return ""
+	}
+	return strings.TrimRight(remote, "/") + "/" + repo_name
 }
 
 func newEmptyProject(sub Submission, config *lib.Config)error {
-	target_path, err := lib.ExpandPath(config.Projects_path)
+	target_path, err := config.NewProjectPath("Empty", sub.folder_name)
     if err != nil{
         return err
     }
-	target_path = target_path + "/"
-	err = createEmptyFolder(target_path, sub.folder_name)
+	err = createEmptyFolder(filepath.Dir(target_path), sub.folder_name)
 	if err != nil {
-		log.Printf("NAU ERROR: Failed to create folder: %v", err)
 		return err
 	}
-    return nil
+	return saveDescription(target_path, sub.description)
+}
+
+// keeps the description in the project metadata so nau can show it later
+func saveDescription(target_path string, description string) error {
+	if strings.TrimSpace(description) == "" {
+		return nil
+	}
+	meta, err := lib.ReadMetadata(target_path)
+	if err != nil {
+		return err
+	}
+	meta.Description = strings.TrimSpace(description)
+	if err := lib.WriteMetadata(target_path, meta); err != nil {
+		return err
+	}
+	//the metadata belongs to nau, not to the repository the project becomes
+	return lib.IgnoreMetadata(target_path)
 }
 
 func createEmptyFolder(target, name string) error {
@@ -286,6 +390,9 @@ func ProcessFile(filename string, data *Data) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if err := data.checkIdentity(tmpl); err != nil {
+		return "", err
+	}
 
 	var result strings.Builder
 	err = tmpl.Execute(&result, data)
@@ -302,6 +409,9 @@ func collapseString(input string, data *Data) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if err := data.checkIdentity(tmpl); err != nil {
+		return "", err
+	}
 	// Execute the template with the provided data object
 	var output bytes.Buffer
 	err = tmpl.Execute(&output, data)
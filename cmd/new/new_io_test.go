@@ -0,0 +1,71 @@
+package new
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"text/template"
+
+	lib "github.com/antonio-leitao/nau/lib"
+)
+
+func TestCheckIdentity(t *testing.T) {
+	tests := []struct {
+		text    string
+		refused bool
+	}{
+		{"{{.Name}} by {{.Author}}", true},
+		{"{{if .Email}}<{{.Email}}>{{end}}", false},
+		{"{{with .Repo}}{{.}}{{end}}", false},
+		{"{{if .Author}}{{.Email}}{{end}}", true},
+		{"{{if .Email}}{{else}}{{.Email}}{{end}}", true},
+		{"{{if and .Author .Email}}{{.Author}} <{{.Email}}>{{end}}", false},
+		{"{{.Name}}", false},
+	}
+	data := Data{Name: "project"}
+	for _, test := range tests {
+		tmpl := template.Must(template.New("README.md").Parse(test.text))
+		if err := data.checkIdentity(tmpl); (err != nil) != test.refused {
+			t.Errorf("checkIdentity(%q) = %v, want refused %v", test.text, err, test.refused)
+		}
+	}
+}
+
+func TestCreateTemplateProjectKeepsExistingFolder(t *testing.T) {
+	templates := t.TempDir()
+	projects := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(templates, "Python_#3776AB"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(templates, "Python_#3776AB", "README.md"), []byte("{{.Author}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	//.nau lists the files that are templated
+	if err := os.WriteFile(filepath.Join(templates, "Python_#3776AB", ".nau"), []byte("README.md\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := &lib.Config{
+		Templates_path: templates,
+		Templates:      map[string]string{"Python": "#3776AB"},
+		Roots:          []lib.Root{{Path: projects}},
+	}
+	existing := filepath.Join(projects, "Python", "ABC_Mine")
+	if err := os.MkdirAll(existing, 0755); err != nil {
+		t.Fatal(err)
+	}
+	sub := Submission{project_name: "mine", folder_name: "ABC_Mine", repo_name: "mine"}
+	if err := createTemplateProject(sub, config, "Python"); err == nil {
+		t.Error("createTemplateProject filled a folder that was already there")
+	}
+	if _, err := os.Stat(existing); err != nil {
+		t.Errorf("the folder that was already there is gone: %s", err)
+	}
+	//a new folder is removed when the template refuses to render
+	sub.folder_name = "ABD_Other"
+	if err := createTemplateProject(sub, config, "Python"); err == nil {
+		t.Error("createTemplateProject rendered {{.Author}} without AUTHOR")
+	}
+	if _, err := os.Stat(filepath.Join(projects, "Python", "ABD_Other")); !os.IsNotExist(err) {
+		t.Errorf("the refused project was left behind: %v", err)
+	}
+}
@@ -1,47 +1,127 @@
 package open
 
 import (
+	"fmt"
+	pick "github.com/antonio-leitao/nau/cmd/pick"
 	lib "github.com/antonio-leitao/nau/lib"
-	"github.com/sahilm/fuzzy"
 	"log"
 	"os"
 	"os/exec"
+	"strings"
 )
 
-type Projects []lib.Project
+// findCommand resolves the program of a launcher on PATH, naming what to fix when it is not there
+func findCommand(config lib.Config, launcher string, args []string) error {
+	if _, err := exec.LookPath(args[0]); err != nil {
+		//the editor can also come through {{.Editor}} in a launcher or @open in a session
+		if editor, _ := lib.SplitCommand(config.Editor); len(editor) > 0 && editor[0] == args[0] {
+			return fmt.Errorf("Could not find the editor %q on PATH, set the one to use with: nau config editor <command>", args[0])
+		}
+		return fmt.Errorf("Could not find %q of the launcher %q on PATH", args[0], launcher)
+	}
+	return nil
+}
 
-func (p Projects) String(i int) string {
-	return p[i].Name
+// Command returns the launcher command for a project, to be run by the caller
+func Command(config lib.Config, project lib.Project, with string) (*exec.Cmd, error) {
+	launcher, err := config.LauncherCommand(project, with)
+	if err != nil {
+		return nil, err
+	}
+	args, err := lib.LaunchArgs(launcher, project, config.Editor)
+	if err != nil {
+		return nil, err
+	}
+	if err := findCommand(config, launcher, args); err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = project.Path
+	return cmd, nil
 }
-func (p Projects) Len() int {
-	return len(p)
+
+// exitWith ends nau with the exit code of the command that failed
+func exitWith(err error) {
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		os.Exit(exitErr.ExitCode())
+	}
+	log.Printf("NAU error: %s", err)
+	os.Exit(1)
 }
-func Open(path string, editor string) {
+
+func Open(config lib.Config, project lib.Project, with string) {
 	// Change to the specified directory
-	if err := os.Chdir(path); err != nil {
+	if err := os.Chdir(project.Path); err != nil {
+		log.Printf("NAU error: %s", err)
+		os.Exit(1)
+	}
+	cmd, err := Command(config, project, with)
+	if err != nil {
 		log.Printf("NAU error: %s", err)
 		os.Exit(1)
 	}
-	// Open Neovim
-	cmd := exec.Command(editor)
+	//the history is only for ranking, failing to write it is not an error
+	lib.RecordOpen(project.Path)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	cmd.Run()
+	if err := cmd.Run(); err != nil {
+		exitWith(err)
+	}
+}
 
+// withTag keeps the projects that have tag
+func withTag(projects []lib.Project, tag string) []lib.Project {
+	var tagged []lib.Project
+	tag = strings.ToLower(tag)
+	for _, project := range projects {
+		for _, t := range project.Tags {
+			if t == tag {
+				tagged = append(tagged, project)
+				break
+			}
+		}
+	}
+	return tagged
 }
 
-func Execute(config lib.Config, query string) {
+func Execute(config lib.Config, query string, tag string, with string, session bool, trust bool) {
 	projectList, _ := lib.GetProjects(config)
-	projects := Projects(projectList)
-	candidates := fuzzy.FindFrom(query, projects)
-
-	//exit it nothing is found
-	if len(candidates) == 0 {
+	if tag != "" {
+		projectList = withTag(projectList, tag)
+	}
+	if len(projectList) == 0 {
 		log.Println("NAU error: No project with a match found")
 		os.Exit(1)
 	}
-	//get project path
-	path := projects[candidates[0].Index].Path
-	Open(path, config.Editor)
+	run := Open
+	if session {
+		run = Session
+	}
+	launch := func(config lib.Config, project lib.Project, with string) {
+		if trust {
+			if err := lib.TrustLaunchers(project.Path); err != nil {
+				log.Printf("NAU error: %s", err)
+				os.Exit(1)
+			}
+		}
+		run(config, project, with)
+	}
+	history := lib.LoadHistory()
+	//without a query the project opened most often lately is opened
+	if query == "" {
+		project, _ := history.MostFrecent(projectList)
+		launch(config, project, with)
+		return
+	}
+	project, err := pick.Resolve(projectList, query, "Open")
+	if err == pick.ErrCancelled {
+		return
+	}
+	//exit it nothing is found
+	if err != nil {
+		log.Printf("NAU error: %s", err)
+		os.Exit(1)
+	}
+	launch(config, project, with)
 }
@@ -0,0 +1,112 @@
+package open
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+
+	lib "github.com/antonio-leitao/nau/lib"
+)
+
+// windowArgs returns the command a window of the session runs, none for a shell
+func windowArgs(config lib.Config, project lib.Project, window lib.Window, with string) ([]string, error) {
+	command := window.Command
+	if command == "" {
+		return nil, nil
+	}
+	if command == lib.OpenWindow {
+		launcher, err := config.LauncherCommand(project, with)
+		if err != nil {
+			return nil, err
+		}
+		command = launcher
+	}
+	args, err := lib.LaunchArgs(command, project, config.Editor)
+	if err != nil {
+		return nil, err
+	}
+	return args, findCommand(config, command, args)
+}
+
+// createTmuxSession starts a detached session with a window for each one of the layout.
+// A session left half made would be attached to as if it were complete, so it is killed on errors.
+func createTmuxSession(tmux string, config lib.Config, project lib.Project, name string, with string) (err error) {
+	windows, err := config.SessionLayout(project)
+	if err != nil {
+		return err
+	}
+	started := false
+	defer func() {
+		if err != nil && started {
+			exec.Command(tmux, "kill-session", "-t", "="+name).Run()
+		}
+	}()
+	for i, window := range windows {
+		args, err := windowArgs(config, project, window, with)
+		if err != nil {
+			return err
+		}
+		var tmuxArgs []string
+		if i == 0 {
+			tmuxArgs = []string{"new-session", "-d", "-s", name, "-c", project.Path, "-n", window.Name}
+		} else {
+			tmuxArgs = []string{"new-window", "-t", "=" + name + ":", "-c", project.Path, "-n", window.Name}
+		}
+		cmd := exec.Command(tmux, append(tmuxArgs, args...)...)
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("Could not create the session %s: %s", name, err)
+		}
+		started = true
+	}
+	//start on the first window, the session is complete without it so a failure is not an error
+	exec.Command(tmux, "select-window", "-t", "="+name+":^").Run()
+	return nil
+}
+
+// SessionCommand returns the command that attaches to the session of a project, creating it first if needed
+func SessionCommand(config lib.Config, project lib.Project, with string) (*exec.Cmd, error) {
+	multiplexer, err := lib.MultiplexerPath(config.Multiplexer)
+	if err != nil {
+		return nil, err
+	}
+	name := lib.SessionName(project)
+	live := lib.LiveSessions(config.Multiplexer)[name]
+	var cmd *exec.Cmd
+	switch config.Multiplexer {
+	case "zellij":
+		//zellij layouts are files of their own, the session starts with a shell
+		cmd = exec.Command(multiplexer, "attach", "--create", name)
+	default:
+		if !live {
+			if err := createTmuxSession(multiplexer, config, project, name, with); err != nil {
+				return nil, err
+			}
+		}
+		if lib.InsideMultiplexer(config.Multiplexer) {
+			cmd = exec.Command(multiplexer, "switch-client", "-t", "="+name)
+		} else {
+			cmd = exec.Command(multiplexer, "attach-session", "-t", "="+name)
+		}
+	}
+	cmd.Dir = project.Path
+	return cmd, nil
+}
+
+// Session attaches to the session of a project, creating it first if needed
+func Session(config lib.Config, project lib.Project, with string) {
+	cmd, err := SessionCommand(config, project, with)
+	if err != nil {
+		log.Printf("NAU error: %s", err)
+		os.Exit(1)
+	}
+	//the history is only for ranking, failing to write it is not an error
+	lib.RecordOpen(project.Path)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		exitWith(err)
+	}
+}
@@ -0,0 +1,136 @@
+package open
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	lib "github.com/antonio-leitao/nau/lib"
+)
+
+// fake tmux that logs its arguments, lists $FAKE_TMUX_LIVE as running and fails the subcommand in $FAKE_TMUX_FAIL
+const fakeTmux = `#!/bin/sh
+echo "$*" >> "$FAKE_TMUX_LOG"
+case "$1" in
+list-sessions)
+	[ -n "$FAKE_TMUX_LIVE" ] || exit 1
+	echo "$FAKE_TMUX_LIVE"
+	;;
+"$FAKE_TMUX_FAIL")
+	exit 1
+	;;
+esac
+`
+
+// fakeMultiplexer puts a fake tmux and editor first on PATH and returns the file tmux logs to
+func fakeMultiplexer(t *testing.T) string {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "tmux"), []byte(fakeTmux), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "vim"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	log := filepath.Join(dir, "tmux.log")
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("FAKE_TMUX_LOG", log)
+	t.Setenv("FAKE_TMUX_LIVE", "")
+	t.Setenv("FAKE_TMUX_FAIL", "")
+	t.Setenv("TMUX", "")
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	return log
+}
+
+// calls returns the arguments of every call to the fake tmux, the list-sessions ones left out
+func calls(t *testing.T, log string) []string {
+	data, err := os.ReadFile(log)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if line != "" && !strings.HasPrefix(line, "list-sessions") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func TestSessionCommand(t *testing.T) {
+	project := lib.Project{Path: t.TempDir(), Code: "ABC", Name: "my_project", Lang: "Python"}
+	session := lib.SessionName(project)
+	config := lib.Config{
+		Editor:      "vim",
+		Multiplexer: "tmux",
+		Per_template: map[string]lib.TemplateOptions{
+			"python": {Session: "editor:@open;repl:vim -u NONE;shell:"},
+		},
+	}
+	tests := []struct {
+		name   string
+		live   string
+		tmux   string
+		fail   string
+		calls  []string
+		attach []string
+		err    bool
+	}{
+		{
+			name: "new session",
+			calls: []string{
+				"new-session -d -s " + session + " -c " + project.Path + " -n editor vim",
+				"new-window -t =" + session + ": -c " + project.Path + " -n repl vim -u NONE",
+				"new-window -t =" + session + ": -c " + project.Path + " -n shell",
+				"select-window -t =" + session + ":^",
+			},
+			attach: []string{"attach-session", "-t", "=" + session},
+		},
+		{
+			name:   "live session",
+			live:   session,
+			attach: []string{"attach-session", "-t", "=" + session},
+		},
+		{
+			name:   "inside tmux",
+			live:   session,
+			tmux:   "/tmp/tmux-0/default,1,0",
+			attach: []string{"switch-client", "-t", "=" + session},
+		},
+		{
+			name: "failed window",
+			fail: "new-window",
+			calls: []string{
+				"new-session -d -s " + session + " -c " + project.Path + " -n editor vim",
+				"new-window -t =" + session + ": -c " + project.Path + " -n repl vim -u NONE",
+				"kill-session -t =" + session,
+			},
+			err: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			log := fakeMultiplexer(t)
+			t.Setenv("FAKE_TMUX_LIVE", test.live)
+			t.Setenv("FAKE_TMUX_FAIL", test.fail)
+			t.Setenv("TMUX", test.tmux)
+			cmd, err := SessionCommand(config, project, "")
+			if (err != nil) != test.err {
+				t.Fatalf("SessionCommand error = %v, want error %v", err, test.err)
+			}
+			if got := calls(t, log); !reflect.DeepEqual(got, test.calls) {
+				t.Errorf("tmux calls = %q, want %q", got, test.calls)
+			}
+			if err != nil {
+				return
+			}
+			if got := cmd.Args[1:]; !reflect.DeepEqual(got, test.attach) {
+				t.Errorf("attach = %q, want %q", got, test.attach)
+			}
+			if cmd.Dir != project.Path {
+				t.Errorf("attach runs in %s, want %s", cmd.Dir, project.Path)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package pick
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	lib "github.com/antonio-leitao/nau/lib"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type keyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Choose key.Binding
+	Quit   key.Binding
+}
+
+var keys = keyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k", "shift+tab"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j", "tab"),
+	),
+	Choose: key.NewBinding(
+		key.WithKeys("enter"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys("q", "esc", "ctrl+c"),
+	),
+}
+
+// a small list shown inline when a query matches several projects
+type model struct {
+	title    string
+	projects []lib.Project
+	cursor   int
+	chosen   bool
+	done     bool
+	muted    lipgloss.Style
+}
+
+func (m model) Init() tea.Cmd {
+	return nil
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, keys.Up):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case key.Matches(msg, keys.Down):
+			if m.cursor < len(m.projects)-1 {
+				m.cursor++
+			}
+		case key.Matches(msg, keys.Choose):
+			m.chosen = true
+			m.done = true
+			return m, tea.Quit
+		case key.Matches(msg, keys.Quit):
+			m.done = true
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+func (m model) View() string {
+	//leave nothing behind
+	if m.done {
+		return ""
+	}
+	lines := []string{m.title}
+	for i, project := range m.projects {
+		name := project.Display_Name
+		if project.Code != "" {
+			name = project.Code + " " + name
+		}
+		where := m.muted.Render("  " + lib.ShortPath(project.Path))
+		if i == m.cursor {
+			lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color(project.Color)).Render("> "+name)+where)
+		} else {
+			lines = append(lines, "  "+name+where)
+		}
+	}
+	lines = append(lines, m.muted.Render("↑/↓ choose • enter confirm • esc cancel"))
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// Choose asks which of projects was meant, false if the user gave up
+func Choose(title string, projects []lib.Project) (lib.Project, bool) {
+	m := model{
+		title:    title,
+		projects: projects,
+		muted:    lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#9B9B9B", Dark: "#5C5C5C"}),
+	}
+	//stdout may be captured, as in cd "$(nau path query)"
+	final, err := tea.NewProgram(m, tea.WithOutput(os.Stderr)).Run()
+	if err != nil {
+		return lib.Project{}, false
+	}
+	m = final.(model)
+	if !m.chosen {
+		return lib.Project{}, false
+	}
+	return m.projects[m.cursor], true
+}
+
+// Confirm asks a yes or no question on the terminal, no unless told otherwise
+func Confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// returned by Resolve when the user does not choose
+var ErrCancelled = errors.New("Cancelled")
+
+// Resolve returns the project query refers to, asking which one when several match about as well
+func Resolve(projects []lib.Project, query string, verb string) (lib.Project, error) {
+	candidates := lib.Resolve(projects, query, lib.LoadHistory())
+	switch len(candidates) {
+	case 0:
+		return lib.Project{}, fmt.Errorf("No project with a match found")
+	case 1:
+		return candidates[0], nil
+	}
+	project, ok := Choose(fmt.Sprintf("%s which project?", verb), candidates)
+	if !ok {
+		return lib.Project{}, ErrCancelled
+	}
+	return project, nil
+}
@@ -0,0 +1,20 @@
+package reindex
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	lib "github.com/antonio-leitao/nau/lib"
+)
+
+func Execute(config lib.Config) {
+	start := time.Now()
+	projects, err := lib.Reindex(config)
+	if err != nil {
+		log.Printf("NAU ERROR: %s", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Indexed %d projects in %s\n", len(projects), time.Since(start).Round(time.Millisecond))
+}
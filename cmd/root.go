@@ -6,11 +6,11 @@ import (
 	"sort"
 	"strings"
 
-	archive "github.com/antonio-leitao/nau/cmd/archive"
-	open "github.com/antonio-leitao/nau/cmd/open"
+	wizard "github.com/antonio-leitao/nau/cmd/new"
 	lib "github.com/antonio-leitao/nau/lib"
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -20,15 +20,44 @@ import (
 
 var (
 	targetProject lib.Project
-	targetAction  string
+	targetAction  *action
 	confirmation  bool
 )
 
 // we gonna need this for filtering and stuff
 type Projects []lib.Project
 
+// free text matches the name, template, root and group, qualifiers narrow them down further
 func (p Projects) String(i int) string {
-	return p[i].Name + p[i].Lang
+	return strings.Join(matchTexts(p[i]), " ")
+}
+
+// texts of a project the free text is matched against, as they are shown
+const (
+	matchName = iota
+	matchLang
+	matchRoot
+)
+
+func matchTexts(project lib.Project) []string {
+	return []string{project.Display_Name, project.Lang, project.Root, project.Group}
+}
+
+// matchedIn returns the matched offsets that fall inside one of the texts of matchTexts, counted from its start
+func matchedIn(project lib.Project, matched []int, text int) []int {
+	texts := matchTexts(project)
+	start := 0
+	for _, t := range texts[:text] {
+		start += len(t) + 1
+	}
+	end := start + len(texts[text])
+	var inside []int
+	for _, i := range matched {
+		if i >= start && i < end {
+			inside = append(inside, i-start)
+		}
+	}
+	return inside
 }
 func (p Projects) Len() int {
 	return len(p)
@@ -37,6 +66,8 @@ func (p Projects) Len() int {
 const (
 	bullet   = "•"
 	ellipsis = "…"
+	//longer names are truncated
+	maxColumnWidth = 32
 )
 
 type Styles struct {
@@ -54,6 +85,10 @@ type Styles struct {
 	StatusBar lipgloss.Style
 	//deco
 	DividerDot lipgloss.Style
+	Detail     lipgloss.Style
+	//details pane
+	Pane      lipgloss.Style
+	PaneTitle lipgloss.Style
 	//colors
 	verySubduedColor lipgloss.AdaptiveColor
 	subduedColor     lipgloss.AdaptiveColor
@@ -84,6 +119,16 @@ func DefaultStyles(base_color string) (s Styles) {
 	s.DividerDot = lipgloss.NewStyle().
 		Foreground(s.verySubduedColor).
 		SetString(" " + bullet + " ")
+	s.Detail = lipgloss.NewStyle().
+		Padding(1, 0, 0, 1)
+	s.Pane = lipgloss.NewStyle().
+		Width(paneWidth).
+		Padding(0, 1).
+		Margin(0, 0, 0, 2).
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderLeft(true).
+		BorderForeground(s.verySubduedColor)
+	s.PaneTitle = lipgloss.NewStyle().Bold(true)
 	s.PromptStyle = lipgloss.NewStyle().
 		Margin(2, 0, 1, 0)
 	s.SelectedStyle = lipgloss.NewStyle().
@@ -108,16 +153,22 @@ type keyMap struct {
 	Down  key.Binding
 	Left  key.Binding
 	Right key.Binding
-	//commands
-	Open    key.Binding
-	Archive key.Binding
+	//views
+	View    key.Binding
+	Sort    key.Binding
+	Reverse key.Binding
+	Group   key.Binding
+	//marks
+	Mark    key.Binding
+	MarkAll key.Binding
 	//filter
 	Filter               key.Binding
 	ClearFilter          key.Binding
 	AcceptWhileFiltering key.Binding
 	//confirmation
-	Enter  key.Binding
-	Toggle key.Binding
+	Enter     key.Binding
+	Toggle    key.Binding
+	NextField key.Binding
 	//general
 	Help   key.Binding
 	Quit   key.Binding
@@ -129,7 +180,7 @@ type keyMap struct {
 // ShortHelp returns keybindings to be shown in the mini help view. It's part
 // of the key.Map interface.
 func (k keyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Open, k.Archive, k.Filter, k.Help, k.Quit}
+	return []key.Binding{actions[0].binding, actions[1].binding, k.Filter, k.Help, k.Quit}
 }
 
 // FullHelp returns keybindings for the expanded help view. It's part of the
@@ -137,7 +188,9 @@ func (k keyMap) ShortHelp() []key.Binding {
 func (k keyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.Left, k.Right}, // first column
-		{k.Open, k.Archive},
+		actionBindings(),
+		{k.View, k.Sort, k.Reverse, k.Group},
+		{k.Mark, k.MarkAll},
 		{k.Filter, k.Help, k.Quit},
 	}
 }
@@ -159,13 +212,29 @@ var keys = keyMap{
 		key.WithKeys("right", "l"),
 		key.WithHelp("→/l", "right"),
 	),
-	Open: key.NewBinding(
-		key.WithKeys("o", "enter"),
-		key.WithHelp("o", "open"),
+	View: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "grid/list"),
+	),
+	Sort: key.NewBinding(
+		key.WithKeys("1", "2", "3", "4", "5", "6"),
+		key.WithHelp("1-6", "sort by column"),
+	),
+	Reverse: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "reverse order"),
 	),
-	Archive: key.NewBinding(
-		key.WithKeys("a", "delete"),
-		key.WithHelp("a", "archive"),
+	Group: key.NewBinding(
+		key.WithKeys("g"),
+		key.WithHelp("g", "group by template"),
+	),
+	Mark: key.NewBinding(
+		key.WithKeys(" "),
+		key.WithHelp("space", "mark"),
+	),
+	MarkAll: key.NewBinding(
+		key.WithKeys("A"),
+		key.WithHelp("A", "mark all shown"),
 	),
 	Filter: key.NewBinding(
 		key.WithKeys("/"),
@@ -193,6 +262,10 @@ var keys = keyMap{
 		key.WithKeys("left", "shift+tab", "tab", "right", "h", "l"),
 		key.WithHelp("←/→/tab", "choose"),
 	),
+	NextField: key.NewBinding(
+		key.WithKeys("tab", "shift+tab"),
+		key.WithHelp("tab", "next field"),
+	),
 	//general
 	Help: key.NewBinding(
 		key.WithKeys("?"),
@@ -218,13 +291,44 @@ type model struct {
 	width            int
 	columnWidth      int
 	numCols          int
+	offset           int //first row of the grid on screen
 	selectedProject  lib.Project
 	styles           Styles
 	gap              int
 	filter           textinput.Model
+	showRoots        bool
+	statuses         map[string]lib.GitStatus
+	sessions         map[string]bool //live multiplexer sessions by name
+	details          map[string]projectDetails
+	sizing           map[string]bool //projects being measured
+	view             string
+	sortBy           string
+	reverse          bool
+	grouped          bool
+	termWidth        int
+	termHeight       int
+	config           lib.Config
+	//actions
+	message   string //outcome of the last action
+	actionErr string
+	inputs    []textinput.Model
+	focus     int
+	options   []string
+	choice    int
+	wizard    wizard.Model
+	//marked projects, the targets of batch actions
+	marked   map[string]bool
+	batching bool
+	batch    batchRun
+	spinner  spinner.Model
+	doing    string //what runs while working
+	//filter
+	query    lib.Query
+	matches  map[string][]int //characters of each name that matched the free text
+	archived []lib.Project    //read the first time archived:true is used
 }
 
-func newModel(base_color string, projects Projects) model {
+func newModel(config lib.Config, projects Projects, state lib.UIState) model {
 	m := model{
 		cursor:           0,
 		initial_projects: projects,
@@ -232,13 +336,24 @@ func newModel(base_color string, projects Projects) model {
 		state:            "browsing",
 		help:             help.New(),
 		width:            70,
-		styles:           DefaultStyles(base_color),
+		styles:           DefaultStyles(config.Base_color),
 		gap:              2,
 		filter:           textinput.New(),
+		showRoots:        len(config.Roots) > 1,
+		config:           config,
+		statuses:         make(map[string]lib.GitStatus),
+		details:          make(map[string]projectDetails),
+		sizing:           make(map[string]bool),
+		marked:           make(map[string]bool),
+		spinner:          spinner.New(),
+		view:             state.View,
+		sortBy:           state.Sort,
+		reverse:          state.Reverse,
+		grouped:          state.Grouped,
 	}
 	confirmation = false
 	m.filter.Prompt = ""
-	m.applyFilter()
+	m.sortProjects()
 	//update really has to be after
 	m.updateGrid()
 	return m
@@ -246,31 +361,70 @@ func newModel(base_color string, projects Projects) model {
 func (m *model) applyFilter() {
 	m.cursor = 0
 	//get query
-	query := m.filter.Value()
-	if query == "" {
-		m.projects = m.initial_projects
+	m.query = lib.ParseQuery(m.filter.Value())
+	source := []lib.Project(m.initial_projects)
+	if m.query.Archived() {
+		source = m.archivedProjects()
+	}
+	//qualifiers first
+	var candidates []lib.Project
+	for _, project := range source {
+		if m.query.Matches(project, m.statuses[project.Path]) {
+			candidates = append(candidates, project)
+		}
+	}
+	m.matches = make(map[string][]int)
+	if m.query.Text == "" {
+		m.projects = candidates
 	} else {
 		//get matches
-		results := fuzzy.FindFrom(query, m.initial_projects)
+		results := fuzzy.FindFrom(m.query.Text, Projects(candidates))
+		//best matches first, the cursor starts on the best one
 		var filteredProjects []lib.Project
 		for _, r := range results {
-			filteredProjects = append(filteredProjects, m.initial_projects[r.Index])
+			project := candidates[r.Index]
+			filteredProjects = append(filteredProjects, project)
+			m.matches[project.Path] = r.MatchedIndexes
 		}
 		//update projects
 		m.projects = filteredProjects
 	}
+	m.offset = 0
+	m.updateGrid()
 	//toggle state if necessary
 	if len(m.projects) < 1 {
 		m.state = "stasis"
+	} else {
+		m.selectedProject = m.projects[m.cursor]
+		targetProject = m.selectedProject
 	}
 }
+
+// archivedProjects reads the archives folder once and orders it like the grid
+func (m *model) archivedProjects() []lib.Project {
+	if m.archived == nil {
+		m.archived, _ = lib.GetArchivedProjects(m.config)
+		if m.archived == nil {
+			m.archived = []lib.Project{}
+		}
+	}
+	archived := append([]lib.Project{}, m.archived...)
+	sort.SliceStable(archived, func(i, j int) bool {
+		if m.reverse {
+			return m.less(archived[j], archived[i])
+		}
+		return m.less(archived[i], archived[j])
+	})
+	return archived
+}
+
 func (m *model) resetFilter() {
 	m.filter.SetValue("")
 	m.applyFilter()
 }
 
 func (m model) Init() tea.Cmd {
-	return nil
+	return tea.Batch(fetchAllGitStatus(m.initial_projects), fetchSessions(m.config.Multiplexer))
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -279,6 +433,49 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.help.Width = msg.Width
+		m.termWidth = msg.Width
+		m.termHeight = msg.Height
+		m.width = m.gridWidth()
+		m.updateGrid()
+		if m.state == "new" {
+			return m, m.handleWizard(msg)
+		}
+	case actionDoneMsg:
+		return m, m.handleActionDone(msg)
+	case refreshMsg:
+		return m, m.handleRefresh(msg)
+	case batchItemMsg:
+		return m, m.handleBatchItem(msg)
+	case spinner.TickMsg:
+		//only spins while something runs
+		running := m.state == "working" || (m.state == "batch" && !m.batch.finished)
+		if !running {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+	case detailsMsg:
+		m.updateDetails(msg)
+		return m, nil
+	case sizeMsg:
+		m.updateDetails(msg)
+		if m.sortBy == "size" {
+			m.resortProjects()
+		}
+		return m, nil
+	case gitStatusMsg:
+		m.statuses[msg.path] = msg.status
+		if m.sortBy == "git" || m.query.Has("dirty") {
+			m.resortProjects()
+		}
+		//badges make cells wider
+		m.updateGrid()
+		return m, nil
+	case sessionsMsg:
+		m.sessions = msg
+		m.updateGrid()
+		return m, nil
 	case tea.KeyMsg:
 		if key.Matches(msg, m.keys.ForceQuit) {
 			m.quitting = true
@@ -292,9 +489,22 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, m.handleStasis(msg))
 	case "confirm":
 		cmds = append(cmds, m.handleConfirmation(msg))
+	case "prompt":
+		cmds = append(cmds, m.handlePrompt(msg))
+	case "choose":
+		cmds = append(cmds, m.handleChoice(msg))
+	case "new":
+		cmds = append(cmds, m.handleWizard(msg))
+	case "batch":
+		cmds = append(cmds, m.handleBatch(msg))
+	case "working":
+		//keys wait until it is done
 	default:
 		cmds = append(cmds, m.handleBrowsing(msg))
 	}
+	//the selection may have changed
+	m.scrollToCursor()
+	cmds = append(cmds, m.loadDetails(), m.loadSizes())
 	return m, tea.Batch(cmds...)
 }
 func (m *model) handleFiltering(msg tea.Msg) tea.Cmd {
@@ -337,6 +547,13 @@ func (m *model) handleStasis(msg tea.Msg) tea.Cmd {
 		case key.Matches(msg, m.keys.Filter):
 			m.state = "filtering"
 			m.filter.Focus()
+		default:
+			for _, a := range actions {
+				if a.anywhere && key.Matches(msg, a.binding) {
+					cmds = append(cmds, m.startAction(a))
+					break
+				}
+			}
 		}
 	}
 	return tea.Batch(cmds...)
@@ -382,15 +599,43 @@ func (m *model) handleBrowsing(msg tea.Msg) tea.Cmd {
 			targetProject = m.selectedProject
 		case key.Matches(msg, m.keys.Help):
 			m.help.ShowAll = !m.help.ShowAll
-		case key.Matches(msg, m.keys.Open):
-			targetAction = "open"
-			m.state = "confirm"
-		case key.Matches(msg, m.keys.Archive):
-			targetAction = "archive"
-			m.state = "confirm"
 		case key.Matches(msg, m.keys.Filter):
 			m.state = "filtering"
 			m.filter.Focus()
+		case key.Matches(msg, m.keys.View):
+			if m.view == "list" {
+				m.view = "grid"
+			} else {
+				m.view = "list"
+			}
+			m.sortProjects()
+		case key.Matches(msg, m.keys.Sort):
+			column := columns[int(msg.String()[0]-'1')]
+			//choosing the same column again reverses it
+			if m.sortBy == column.key {
+				m.reverse = !m.reverse
+			} else {
+				m.sortBy = column.key
+				m.reverse = false
+			}
+			m.sortProjects()
+		case key.Matches(msg, m.keys.Reverse):
+			m.reverse = !m.reverse
+			m.sortProjects()
+		case key.Matches(msg, m.keys.Group):
+			m.grouped = !m.grouped
+			m.sortProjects()
+		case key.Matches(msg, m.keys.Mark):
+			m.toggleMark(m.selectedProject)
+		case key.Matches(msg, m.keys.MarkAll):
+			m.markAll()
+		default:
+			for _, a := range actions {
+				if key.Matches(msg, a.binding) {
+					cmds = append(cmds, m.startAction(a))
+					break
+				}
+			}
 		}
 	}
 	return tea.Batch(cmds...)
@@ -406,14 +651,12 @@ func (m *model) handleConfirmation(msg tea.Msg) tea.Cmd {
 		//submission
 		case key.Matches(msg, m.keys.Enter):
 			if confirmation {
-				m.quitting = true
-				return tea.Quit
+				return m.finishAction(nil)
 			} else {
 				m.state = "browsing"
 			}
-		case key.Matches(msg, m.keys.Cancel):
-			m.state = "browsing"
-		case key.Matches(msg, m.keys.Quit):
+		case key.Matches(msg, m.keys.Cancel), key.Matches(msg, m.keys.Quit):
+			confirmation = false
 			m.state = "browsing"
 		case key.Matches(msg, m.keys.Help):
 			m.help.ShowAll = !m.help.ShowAll
@@ -426,8 +669,19 @@ func (m model) View() string {
 	if m.quitting {
 		return ""
 	}
-	if m.state == "confirm" {
+	switch m.state {
+	case "confirm":
 		return m.confirmationView()
+	case "prompt":
+		return m.promptView()
+	case "choose":
+		return m.choiceView()
+	case "new":
+		return m.wizard.View()
+	case "batch":
+		return m.batchView()
+	case "working":
+		return m.workingView()
 	}
 	//header
 	var sections []string
@@ -439,8 +693,11 @@ func (m model) View() string {
 			sections,
 			m.styles.notFound.Render("No projects found :("),
 		)
+	} else if m.showDetails() {
+		sections = append(sections, lipgloss.JoinHorizontal(lipgloss.Top, m.projectsView(), m.detailsView()))
 	} else {
-		sections = append(sections, m.GridView())
+		sections = append(sections, m.projectsView())
+		sections = append(sections, m.gitView())
 	}
 	//help
 	helpView := m.help.View(m.keys)
@@ -452,8 +709,13 @@ func (m model) confirmationView() string {
 
 	var aff, neg string
 	var sections []string
-	query := fmt.Sprintf("%s %s?", strings.Title(targetAction), targetProject.Display_Name)
+	query := fmt.Sprintf("%s %s?", strings.Title(targetAction.name), targetProject.Display_Name)
 	sections = append(sections, m.styles.PromptStyle.Render(query))
+	//warn about work that was never committed
+	if status := m.statuses[targetProject.Path]; targetAction.name == "archive" && status.Dirty() {
+		warning := fmt.Sprintf("%d changed and %d untracked files are not committed", status.Changed, status.Untracked)
+		sections = append(sections, m.styles.BlurredStyle.Render(warning))
+	}
 
 	if confirmation {
 		aff = m.styles.SelectedStyle.Render("Yes")
@@ -473,12 +735,19 @@ func (m model) statusView() string {
 		//title
 		status += m.styles.Title.Render(`|\| /\ |_|`)
 		//filter status
-		if m.filter.Value() != "" {
+		if m.query.Text != "" {
 			status += m.styles.DividerDot.String()
-			f := strings.TrimSpace(m.filter.Value())
-			f = truncate.StringWithTail(f, 10, "…")
+			f := truncate.StringWithTail(m.query.Text, 10, "…")
 			status += m.styles.Count.Render(fmt.Sprintf("“%s” ", f))
 		}
+		for _, qualifier := range m.query.Qualifiers {
+			status += m.styles.DividerDot.String()
+			status += m.styles.Count.Render(qualifier.String())
+		}
+		for _, err := range m.query.Errors {
+			status += m.styles.DividerDot.String()
+			status += m.styles.BlurredStyle.Render(err)
+		}
 		//number of items
 		totalItems := len(m.projects)
 		var itemName string
@@ -490,6 +759,21 @@ func (m model) statusView() string {
 		status += m.styles.DividerDot.String()
 		itemsDisplay := fmt.Sprintf("%d %s", totalItems, itemName)
 		status += m.styles.Count.Render(itemsDisplay)
+		if len(m.marked) > 0 {
+			status += m.styles.DividerDot.String()
+			status += m.styles.Count.Render(fmt.Sprintf("%d marked", len(m.marked)))
+		}
+		//outcome of the last action
+		if m.message != "" {
+			status += m.styles.DividerDot.String()
+			status += m.styles.Count.Render(m.message)
+		}
+		//position when not all rows fit
+		if m.scrolls() {
+			first, last := m.visibleRange()
+			status += m.styles.DividerDot.String()
+			status += m.styles.Count.Render(fmt.Sprintf("%d–%d", first, last))
+		}
 	} else {
 		//if we are filtering show the input thing
 		status += m.styles.Title.Render("Filter: ")
@@ -498,12 +782,24 @@ func (m model) statusView() string {
 	return m.styles.StatusBar.Render(status)
 }
 
+func (m model) projectsView() string {
+	if m.view == "list" {
+		return m.ListView()
+	}
+	return m.GridView()
+}
+
 func (m model) GridView() string {
 	// Create a new slice to store the modified strings
 	modifiedStrings := make([]string, len(m.projects))
 	// Print the grid
 	var rows []string
-	for i := 0; i < len(m.projects); i += m.numCols {
+	start := m.offset * m.numCols
+	stop := (m.offset + m.visibleRows()) * m.numCols
+	if stop > len(m.projects) {
+		stop = len(m.projects)
+	}
+	for i := start; i < stop; i += m.numCols {
 		end := i + m.numCols
 		if end > len(m.projects) {
 			end = len(m.projects)
@@ -534,70 +830,239 @@ func (m model) renderProject(index int) string {
 		title_text = "235"
 	}
 	if m.cursor == index {
-		return lipgloss.NewStyle().
+		selected := lipgloss.NewStyle().
+			Background(lipgloss.Color(project.Color)).
+			Foreground(lipgloss.Color(title_text))
+		return selected.Copy().
 			Width(m.columnWidth-m.gap).
 			Margin(0, m.gap, 0, 0).
-			Background(lipgloss.Color(project.Color)).
-			Foreground(lipgloss.Color(title_text)).
-			Render(project.Display_Name)
+			Render(m.fitCell(m.styledTitle(project, selected, true) + selected.Render(m.gitBadge(project))))
 
 	} else {
 		return lipgloss.NewStyle().
 			Width(m.columnWidth-m.gap).
 			Margin(0, m.gap, 0, 0).
-			Render(project.Display_Name)
+			Render(m.fitCell(m.styledTitle(project, lipgloss.NewStyle(), false) + m.styles.BlurredStyle.Render(m.gitBadge(project))))
 	}
 }
 func (m model) renderDimmedProject(index int) string {
 	project := m.projects[index]
+	dimmed := lipgloss.NewStyle().Foreground(m.styles.subduedColor)
 
 	return lipgloss.NewStyle().
 		Width(m.columnWidth-m.gap).
-		Foreground(m.styles.subduedColor).
 		Margin(0, m.gap, 0, 0).
-		Render(project.Display_Name)
+		Render(m.fitCell(m.styledTitle(project, dimmed, false) + dimmed.Render(m.gitBadge(project))))
+}
+
+// name shown in the grid, with the root label when there is more than one root
+func (m model) projectTitle(project lib.Project) string {
+	return m.titlePrefix(project) + project.Display_Name + m.rootSuffix(project)
+}
+
+// projectTitle with the characters that matched the filter emphasized
+func (m model) styledTitle(project lib.Project, base lipgloss.Style, selected bool) string {
+	hit := hitStyle(project, base, selected)
+	matched := m.matches[project.Path]
+	title := highlight(project.Display_Name, matchedIn(project, matched, matchName), base, hit)
+	if prefix := m.titlePrefix(project); prefix != "" {
+		title = base.Render(prefix) + title
+	}
+	if suffix := m.rootSuffix(project); suffix != "" {
+		title += base.Render(strings.TrimSuffix(suffix, project.Root)) + highlight(project.Root, matchedIn(project, matched, matchRoot), base, hit)
+	}
+	return title
+}
+
+// the template of a project with the characters that matched the filter emphasized
+func (m model) styledLang(project lib.Project, base lipgloss.Style, selected bool) string {
+	hit := hitStyle(project, base, selected)
+	return highlight(project.Lang, matchedIn(project, m.matches[project.Path], matchLang), base, hit)
+}
+
+// marks and pins, names stay aligned while there are marks
+func (m model) titlePrefix(project lib.Project) string {
+	prefix := ""
+	if m.marked[project.Path] {
+		prefix = "✓ "
+	} else if len(m.marked) > 0 {
+		prefix = "  "
+	}
+	if project.Pinned {
+		prefix += "★ "
+	}
+	if m.hasSession(project) {
+		prefix += "▶ "
+	}
+	return prefix
+}
+
+func (m model) rootSuffix(project lib.Project) string {
+	if !m.showRoots || project.Root == "" {
+		return ""
+	}
+	return " " + bullet + " " + project.Root
+}
+
+// style of the characters that matched the filter
+func hitStyle(project lib.Project, base lipgloss.Style, selected bool) lipgloss.Style {
+	hit := base.Copy().Bold(true).Underline(true)
+	//the selected project already has its color as background
+	if !selected {
+		hit = hit.Foreground(lipgloss.Color(project.Color))
+	}
+	return hit
+}
+
+// highlight renders the characters of name at the matched byte offsets with hit
+func highlight(name string, matched []int, base lipgloss.Style, hit lipgloss.Style) string {
+	if len(matched) == 0 {
+		return base.Render(name)
+	}
+	isHit := make(map[int]bool)
+	for _, i := range matched {
+		isHit[i] = true
+	}
+	var out, run strings.Builder
+	runHit := false
+	flush := func() {
+		if run.Len() == 0 {
+			return
+		}
+		if runHit {
+			out.WriteString(hit.Render(run.String()))
+		} else {
+			out.WriteString(base.Render(run.String()))
+		}
+		run.Reset()
+	}
+	for i, c := range name {
+		if isHit[i] != runHit {
+			flush()
+			runHit = isHit[i]
+		}
+		run.WriteRune(c)
+	}
+	flush()
+	return out.String()
 }
 
 func (m model) getColumnWidth() int {
 	columnWidth := 0
 	for _, project := range m.projects {
-		if len(project.Display_Name) > columnWidth {
-			columnWidth = len(project.Display_Name)
+		width := lipgloss.Width(m.projectTitle(project) + m.gitBadge(project))
+		if width > columnWidth {
+			columnWidth = width
 		}
 	}
-	return columnWidth + m.gap
+	columnWidth += m.gap
+	if columnWidth > maxColumnWidth {
+		columnWidth = maxColumnWidth
+	}
+	if columnWidth > m.width {
+		columnWidth = m.width
+	}
+	return columnWidth
+}
+
+// truncates the content of a cell to the width of the column
+func (m model) fitCell(content string) string {
+	width := m.columnWidth - m.gap
+	if width < 1 {
+		width = 1
+	}
+	if lipgloss.Width(content) <= width {
+		return content
+	}
+	return truncate.StringWithTail(content, uint(width), ellipsis)
+}
+
+// width left for the grid by the terminal and the details pane
+func (m model) gridWidth() int {
+	width := m.termWidth - 1
+	if m.showDetails() {
+		width -= paneWidth + 3
+	}
+	if width < 1 {
+		width = 1
+	}
+	return width
+}
+
+func (m model) numRows() int {
+	return (len(m.projects) + m.numCols - 1) / m.numCols
+}
+
+// number of rows that fit on the terminal
+func (m model) visibleRows() int {
+	if m.termHeight == 0 {
+		if m.view == "list" {
+			return len(m.listLines()) + 1
+		}
+		return m.numRows()
+	}
+	//status bar and help
+	chrome := 3 + 1 + lipgloss.Height(m.help.View(m.keys))
+	if !m.showDetails() {
+		chrome += lipgloss.Height(m.gitView())
+	}
+	rows := m.termHeight - chrome
+	if rows < 1 {
+		rows = 1
+	}
+	return rows
+}
+
+// moves the visible rows so the cursor is on screen
+func (m *model) scrollToCursor() {
+	if m.view == "list" {
+		m.scrollList()
+		return
+	}
+	row := m.cursor / m.numCols
+	if row < m.offset {
+		m.offset = row
+	}
+	if row >= m.offset+m.visibleRows() {
+		m.offset = row - m.visibleRows() + 1
+	}
+	//do not leave empty rows at the end after a resize
+	if m.offset > m.numRows()-m.visibleRows() {
+		m.offset = m.numRows() - m.visibleRows()
+	}
+	if m.offset < 0 {
+		m.offset = 0
+	}
 }
 func (m *model) updateGrid() {
 	// Calculate the column widths
 	m.columnWidth = m.getColumnWidth()
 	//number of columns
 	m.numCols = m.width / m.columnWidth
+	if m.numCols < 1 || m.view == "list" {
+		m.numCols = 1
+	}
+	m.scrollToCursor()
 }
 
 func Execute(config lib.Config) {
 	//read projects
 	projects, _ := lib.GetProjects(config)
-	// Extract the names from the projects
-	sort.SliceStable(projects, func(i, j int) bool {
-		return projects[i].Timestamp.After(projects[j].Timestamp)
-	})
-	//instantiate model
-	model := newModel(
-		config.Base_color,
-		Projects(projects),
-	)
+	//instantiate model, it sorts the projects
+	m := newModel(config, Projects(projects), lib.LoadUIState())
 	//run the cli
-	if _, err := tea.NewProgram(model).Run(); err != nil {
+	final, err := tea.NewProgram(m).Run()
+	if err != nil {
 		fmt.Printf("Could not start program :(\n%v\n", err)
 		os.Exit(1)
 	}
-	if !confirmation {
-		return
+	//remember the view for next time
+	if final, ok := final.(model); ok {
+		lib.SaveUIState(final.uiState())
 	}
-	switch targetAction {
-	case "open":
-		open.Open(targetProject.Path, config.Editor)
-	case "archive":
-		archive.Archive(targetProject.Path, config.Archives_path)
+	//actions that need the terminal to themselves run after the program
+	if !confirmation || targetAction == nil || targetAction.after == nil {
+		return
 	}
+	targetAction.after(config, targetProject)
 }
@@ -0,0 +1,77 @@
+package shell
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+
+	pick "github.com/antonio-leitao/nau/cmd/pick"
+	lib "github.com/antonio-leitao/nau/lib"
+)
+
+// Shells that init-shell supports
+var Shells = []string{"bash", "zsh", "fish"}
+
+// name of the shell function unless another is given, nc would hide netcat
+const DefaultFunction = "ncd"
+
+var functionNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// functions that cd into the project nau path finds, %[1]s is the name of the function
+const posixFunction = `# nau shell integration, jump to a project with: %[1]s <project>
+%[1]s() {
+	local dir
+	dir="$(command nau path "$@")" && cd "$dir"
+}
+`
+
+const fishFunction = `# nau shell integration, jump to a project with: %[1]s <project>
+function %[1]s
+	set -l dir (command nau path $argv); and cd $dir
+end
+`
+
+// Path prints the path of the project that best matches query, the most frecent one without a query
+func Path(config lib.Config, query string) {
+	projects, _ := lib.GetProjects(config)
+	var project lib.Project
+	if query == "" {
+		var ok bool
+		project, ok = lib.LoadHistory().MostFrecent(projects)
+		if !ok {
+			log.Println("NAU error: No projects found")
+			os.Exit(1)
+		}
+	} else {
+		var err error
+		project, err = pick.Resolve(projects, query, "Go to")
+		if err == pick.ErrCancelled {
+			os.Exit(1)
+		}
+		if err != nil {
+			log.Printf("NAU error: %s", err)
+			os.Exit(1)
+		}
+	}
+	//going to a project counts as opening it
+	lib.RecordOpen(project.Path)
+	fmt.Println(project.Path)
+}
+
+// Init prints the shell function for shell under the given name
+func Init(shell string, name string) {
+	if !functionNameRegex.MatchString(name) {
+		log.Printf("NAU error: %q is not a valid function name", name)
+		os.Exit(1)
+	}
+	switch shell {
+	case "bash", "zsh":
+		fmt.Printf(posixFunction, name)
+	case "fish":
+		fmt.Printf(fishFunction, name)
+	default:
+		log.Printf("NAU error: Unsupported shell %s, use one of bash, zsh or fish", shell)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,81 @@
+package root
+
+import (
+	lib "github.com/antonio-leitao/nau/lib"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// limits how many git processes run at the same time
+var gitSlots = make(chan struct{}, 8)
+
+type gitStatusMsg struct {
+	path   string
+	status lib.GitStatus
+}
+
+// fetchGitStatus reads the git status of a project in the background
+func fetchGitStatus(path string) tea.Cmd {
+	return func() tea.Msg {
+		gitSlots <- struct{}{}
+		defer func() { <-gitSlots }()
+		status, _ := lib.GetGitStatus(path)
+		return gitStatusMsg{path: path, status: status}
+	}
+}
+
+func fetchAllGitStatus(projects []lib.Project) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, project := range projects {
+		cmds = append(cmds, fetchGitStatus(project.Path))
+	}
+	return tea.Batch(cmds...)
+}
+
+// sent with the names of the multiplexer sessions running
+type sessionsMsg map[string]bool
+
+// fetchSessions lists the live sessions in the background
+func fetchSessions(multiplexer string) tea.Cmd {
+	return func() tea.Msg {
+		return sessionsMsg(lib.LiveSessions(multiplexer))
+	}
+}
+
+// hasSession tells whether the project has a live multiplexer session
+func (m model) hasSession(project lib.Project) bool {
+	return m.sessions[lib.SessionName(project)]
+}
+
+// badge shown in the grid cell of a project
+func (m model) gitBadge(project lib.Project) string {
+	status, ok := m.statuses[project.Path]
+	if !ok || !status.IsRepo {
+		return ""
+	}
+	return " " + status.Badge()
+}
+
+// line under the grid with the git status of the selected project
+func (m model) gitView() string {
+	if len(m.projects) == 0 {
+		return ""
+	}
+	project := m.projects[m.cursor]
+	status, ok := m.statuses[project.Path]
+	if !ok {
+		return m.styles.Detail.Render("reading git status…")
+	}
+	var parts []string
+	for i, part := range status.Summary() {
+		if i > 0 {
+			parts = append(parts, m.styles.DividerDot.String())
+		}
+		style := m.styles.Count
+		if i == 0 || (status.Dirty() && i < 3) {
+			style = m.styles.Title
+		}
+		parts = append(parts, style.Render(part))
+	}
+	return m.styles.Detail.Render(lipgloss.JoinHorizontal(lipgloss.Left, parts...))
+}
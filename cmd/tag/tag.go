@@ -0,0 +1,71 @@
+package tag
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	pick "github.com/antonio-leitao/nau/cmd/pick"
+	lib "github.com/antonio-leitao/nau/lib"
+)
+
+func findProject(config lib.Config, query string, verb string) lib.Project {
+	projects, _ := lib.GetProjects(config)
+	project, err := pick.Resolve(projects, query, verb)
+	if err == pick.ErrCancelled {
+		os.Exit(0)
+	}
+	//exit it nothing is found
+	if err != nil {
+		log.Printf("NAU error: %s", err)
+		os.Exit(1)
+	}
+	return project
+}
+
+func exitOnError(err error) {
+	if err != nil {
+		log.Printf("NAU ERROR: %s", err)
+		os.Exit(1)
+	}
+}
+
+func printTags(project lib.Project) {
+	meta, err := lib.ReadMetadata(project.Path)
+	exitOnError(err)
+	if len(meta.Tags) == 0 {
+		fmt.Printf("%s has no tags\n", project.Display_Name)
+		return
+	}
+	fmt.Printf("%s: %s\n", project.Display_Name, strings.Join(meta.Tags, ", "))
+}
+
+// Execute adds tags to the project that best matches query
+func Execute(config lib.Config, query string, tags []string) {
+	project := findProject(config, query, "Tag")
+	exitOnError(lib.AddTags(project.Path, tags))
+	printTags(project)
+}
+
+// Untag removes tags from the project that best matches query, all of them if none are given
+func Untag(config lib.Config, query string, tags []string) {
+	project := findProject(config, query, "Untag")
+	exitOnError(lib.RemoveTags(project.Path, tags))
+	printTags(project)
+}
+
+// Pin keeps the project that best matches query at the top of the grid, or stops doing so
+func Pin(config lib.Config, query string, pinned bool) {
+	verb := "Pin"
+	if !pinned {
+		verb = "Unpin"
+	}
+	project := findProject(config, query, verb)
+	exitOnError(lib.SetPinned(project.Path, pinned))
+	if pinned {
+		fmt.Printf("Pinned %s\n", project.Display_Name)
+	} else {
+		fmt.Printf("Unpinned %s\n", project.Display_Name)
+	}
+}
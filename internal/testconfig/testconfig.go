@@ -0,0 +1,32 @@
+// Package testconfig points nau at a config file made for a test
+package testconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Use points NAU_CONFIG at a config file in a temporary folder holding text, or at a missing file
+// when text is empty. The NAU_ variables of the environment running the tests are cleared so they
+// cannot change the config. It returns the path of the file.
+func Use(t *testing.T, text string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if text != "" {
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, variable := range os.Environ() {
+		name, _, _ := strings.Cut(variable, "=")
+		if strings.HasPrefix(name, "NAU_") {
+			//Setenv restores the variable once the test is done
+			t.Setenv(name, "")
+			os.Unsetenv(name)
+		}
+	}
+	t.Setenv("NAU_CONFIG", path)
+	return path
+}
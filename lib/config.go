@@ -1,19 +1,21 @@
 package lib
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
     "strings"
     "reflect"
     "regexp"
-	"bufio"
+    "strconv"
 )
 
 var version = "v0.3.0"
 
 type Config struct {
-	Version        string
-	Url            string
+	Version        string `toml:"-"`
+	Url            string `toml:"-"`
 	Author         string
 	Email          string
 	Website        string
@@ -23,78 +25,149 @@ type Config struct {
 	Templates_path string
 	Archives_path  string
 	Editor         string
-	Templates      map[string]string
-	Projects       int
+	Folder_pattern string
+	Code_length    int
+	Stay_open      bool
+	Open           string            //command that opens projects instead of the editor
+	Launchers      map[string]string //named commands for open --with
+	Multiplexer    string
+	Session        string                     //windows of project sessions
+	Per_template   map[string]TemplateOptions `toml:"templates"` //by lowercase template name
+	Roots          []Root
+	Templates      map[string]string `toml:"-"`
+	Projects       int               `toml:"-"`
+	Origins        map[string]string `toml:"-"` //where each customizable field was set
+}
+
+// settings of the projects of one template
+type TemplateOptions struct {
+	Open    string //launcher
+	Session string //layout
 }
 
 // these have to be lowercase for better matching
-var CustomizableFields = []string{"AUTHOR", "EMAIL", "REMOTE", "BASE_COLOR", "EDITOR", "PROJECTS_PATH", "TEMPLATES_PATH", "ARCHIVES_PATH"}
+var CustomizableFields = []string{"AUTHOR", "EMAIL", "REMOTE", "BASE_COLOR", "EDITOR", "PROJECTS_PATH", "TEMPLATES_PATH", "ARCHIVES_PATH", "FOLDER_PATTERN", "CODE_LENGTH", "STAY_OPEN", "MULTIPLEXER"}
+// ReadConfig reads the config file over the defaults, with NAU_<FIELD> variables and --set on top
 func ReadConfig() (Config, error) {
+	config, err := ReadSavedConfig()
+	if err != nil {
+		return Config{}, err
+	}
+	return applyOverrides(config)
+}
+
+// ReadSavedConfig reads the config file over the defaults, what nau config starts from and writes back
+func ReadSavedConfig() (Config, error) {
     //read CONFIG file!
 	defaultConfig := Config{
 		Url:            "https://github.com/antonio-leitao/nau",
-		Author:         "Antonio Leitao",
-		Website:        "https://antonio-leitao.github.io",
-		Email:          "aleitao@novaims.unl.pt",
-		Remote:         "https://github.com/antonio-leitao",
 		Base_color:     "#814584",
-		Editor:         "nvim",
+		Editor:         defaultEditor(),
 		Projects_path:  "~/Projects",
 		Templates_path: "~/Templates",
 		Archives_path:  "~/Archives",
+		Folder_pattern: DefaultFolderPattern,
+		Code_length:    3,
+		Multiplexer:    "tmux",
+		Origins:        make(map[string]string),
+	}
+	for _, field := range CustomizableFields {
+		defaultConfig.Origins[field] = OriginDefault
+	}
+	configFile, err := ConfigPath()
+	if err != nil {
+		return Config{}, err
 	}
-    //if file not exsits defaults config
-	configFile, err := ExpandPath("~/.config/naurc")
 	if _, err := os.Stat(configFile); os.IsNotExist(err) {
-		return defaultConfig, nil
+		//older versions kept KEY=VALUE lines in naurc, only the default location takes them over
+		legacyFile, err := ExpandPath(legacyConfigPath)
+		if configGiven() != "" || err != nil || !fileExists(legacyFile) {
+			defaultConfig.Roots, err = ParseRoots(defaultConfig.Projects_path)
+			if err != nil {
+				return Config{}, err
+			}
+			return defaultConfig.withGitIdentity(), nil
+		}
+		if err := migrateConfig(legacyFile, configFile); err != nil {
+			return Config{}, fmt.Errorf("Could not migrate %s: %s", legacyFile, err)
+		}
+		fmt.Fprintf(os.Stderr, "Moved the configuration in %s to %s\n", legacyFile, configFile)
 	}
-
-	file, err := os.Open(configFile)
+	config, err := readConfigFile(configFile, defaultConfig)
 	if err != nil {
 		return Config{}, err
 	}
-	defer file.Close()
+	return config.withGitIdentity(), nil
+}
 
-	config := defaultConfig
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			return Config{}, fmt.Errorf("Invalid config format")
-		}
+// git settings the identity fields default to
+const (
+	gitName       = "user.name"
+	gitEmail      = "user.email"
+	gitGithubUser = "github.user"
+)
 
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
+// withGitIdentity fills the identity fields the config file leaves out from the git configuration.
+// git only runs when one of them is missing, and then only once.
+func (c Config) withGitIdentity() Config {
+	if c.Origins["AUTHOR"] != OriginDefault && c.Origins["EMAIL"] != OriginDefault && c.Origins["REMOTE"] != OriginDefault {
+		return c
+	}
+	settings := gitConfig(gitName, gitEmail, gitGithubUser)
+	if c.Origins["AUTHOR"] == OriginDefault {
+		c.Author = settings[gitName]
+	}
+	if c.Origins["EMAIL"] == OriginDefault {
+		c.Email = settings[gitEmail]
+	}
+	//the GitHub account of github.user
+	if c.Origins["REMOTE"] == OriginDefault && settings[gitGithubUser] != "" {
+		c.Remote = "https://github.com/" + settings[gitGithubUser]
+	}
+	return c
+}
 
-		switch key {
-		case "AUTHOR":
-			config.Author = value
-		case "EMAIL":
-			config.Email = value
-		case "WEBSITE":
-			config.Website = value
-		case "REMOTE":
-			config.Remote = value
-		case "EDITOR":
-			config.Editor = value
-		case "BASE_COLOR":
-			config.Base_color = value
-		case "PROJECTS_PATH":
-			config.Projects_path = value
-		case "TEMPLATES_PATH":
-			config.Templates_path = value
-		case "ARCHIVES_PATH":
-			config.Archives_path = value
-		default:
-			return Config{}, fmt.Errorf("Unknown config field: %s", key)
+// gitConfig returns the values of the git configuration for keys, leaving out the ones that are
+// not set and all of them when git is missing
+func gitConfig(keys ...string) map[string]string {
+	settings := make(map[string]string)
+	quoted := make([]string, len(keys))
+	for i, key := range keys {
+		quoted[i] = regexp.QuoteMeta(key)
+	}
+	output, err := exec.Command("git", "config", "--get-regexp", "^("+strings.Join(quoted, "|")+")$").Output()
+	if err != nil {
+		return settings
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), " ", 2)
+		if len(parts) == 2 {
+			settings[parts[0]] = strings.TrimSpace(parts[1])
 		}
 	}
-	if err := scanner.Err(); err != nil {
-		return Config{}, err
+	return settings
+}
+
+// defaultEditor is the editor of the environment, nvim without one
+func defaultEditor() string {
+	for _, variable := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(variable)); editor != "" {
+			return editor
+		}
 	}
-	return config, nil
+	return "nvim"
+}
+
+// ConfigExists tells whether there is a config file to read, or a naurc to take over
+func ConfigExists() bool {
+	path, err := ConfigPath()
+	if err == nil && fileExists(path) {
+		return true
+	}
+	legacyFile, err := ExpandPath(legacyConfigPath)
+	return configGiven() == "" && err == nil && fileExists(legacyFile)
 }
+
 //############## EXPOSED FUNCTIONS #################s
 // function to load the config stuff
 func LoadConfig() (Config, error) {
@@ -129,7 +202,7 @@ func isCustomizableField(field string) bool {
 			return true
 		}
 	}
-	return false
+	return isLauncherField(field) || isSessionField(field)
 }
 
 func dirExists(path string) bool {
@@ -157,15 +230,18 @@ func isValidHexColor(color string) bool {
 }
 func ValidateValue(field string, value string) string {
 	switch field {
+	//identity fields can be unset, templates that use them refuse to render
 	case "EMAIL":
-		if !isEmailValid(value) {
+		if value != "" && !isEmailValid(value) {
 			return "• Email is not valid"
 		}
 	case "REMOTE", "WEBSITE":
-		if !isValidUrl(value) {
+		if value != "" && !isValidUrl(value) {
 			return "• Url is not valid"
 		}
-	case "PROJECTS_PATH", "TEMPLATES_PATH", "ARCHIVES_PATH":
+	case "PROJECTS_PATH":
+		return validateRoots(value)
+	case "TEMPLATES_PATH", "ARCHIVES_PATH":
 		path, err := ExpandPath(value)
 		if err != nil {
 			return "• " + err.Error()
@@ -177,6 +253,27 @@ func ValidateValue(field string, value string) string {
 		if !isValidHexColor(value) {
 			return "• Not a valid hex color"
 		}
+	case "FOLDER_PATTERN":
+		return ValidateFolderPattern(value)
+	case "CODE_LENGTH":
+		return ValidateCodeLength(value)
+	case "STAY_OPEN":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return "• Use true or false"
+		}
+	case "EDITOR":
+		return validateEditor(value)
+	case "MULTIPLEXER":
+		if !containsString(Multiplexers, value) {
+			return "• Use " + strings.Join(Multiplexers, " or ")
+		}
+	default:
+		switch {
+		case isSessionField(field):
+			return validateLayout(value)
+		case isLauncherField(field):
+			return validateLauncher(value)
+		}
 	}
 	return ""
 }
@@ -190,87 +287,49 @@ func UpdateConfigField(field string, value string) error {
 	//check if the values are correct
 	err_string := ValidateValue(field, value)
 	if err_string != "" {
-		return fmt.Errorf(err_string)
-	}
-	//migh tnot me able to get user
-	configFile, err := ExpandPath("~/.config/naurc")
-	if err != nil {
-		return err
+		return errors.New(err_string)
 	}
-	if _, err := os.Stat(configFile); os.IsNotExist(err) {
-		// Create an empty config file if it does not exist
-		if _, err := os.Create(configFile); err != nil {
-			return err
-		}
-	}
-
-	file, err := os.OpenFile(configFile, os.O_RDWR, 0644)
+	configFile, err := ConfigPath()
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	var lines []string
-	found := false
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			return fmt.Errorf("Invalid config format")
-		}
-
-		key := strings.TrimSpace(parts[0])
-
-		if key == field {
-			lines = append(lines, fmt.Sprintf("%s=%s", field, value))
-			found = true
-		} else {
-			lines = append(lines, line)
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return err
-	}
-
-	if !found {
-		lines = append(lines, fmt.Sprintf("%s=%s", field, value))
-	}
-
-	if _, err := file.Seek(0, 0); err != nil {
+	text := configHeader
+	if data, err := os.ReadFile(configFile); err == nil {
+		text = string(data)
+	} else if !os.IsNotExist(err) {
 		return err
 	}
-
-	if err := file.Truncate(0); err != nil {
+	text, err = setConfigField(text, field, value)
+	if err != nil {
 		return err
 	}
+	return writeConfigFile(configFile, text)
+}
 
-	writer := bufio.NewWriter(file)
-	for _, line := range lines {
-		fmt.Fprintln(writer, line)
+func OutputField(config interface{}, field string) {
+	value, ok := FieldValue(config, field)
+	if !ok {
+		fmt.Println("!")
+		return
 	}
-	return writer.Flush()
+	fmt.Println(value)
 }
 
-func OutputField(config interface{}, field string) {
+// FieldValue returns the value of a field of config as text, false if there is no such field
+func FieldValue(config interface{}, field string) (string, bool) {
 	configValue := reflect.ValueOf(config)
 	if configValue.Kind() == reflect.Ptr {
 		configValue = configValue.Elem()
 	}
 	if configValue.Kind() != reflect.Struct {
-		fmt.Println("!")
-		return
+		return "", false
 	}
 	field = strings.ToUpper(field)
 	fieldValue := configValue.FieldByNameFunc(func(fieldName string) bool {
 		return strings.ToUpper(fieldName) == field
 	})
 	if !fieldValue.IsValid() {
-		fmt.Println("!")
-		return
+		return "", false
 	}
-	fmt.Println(fieldValue.Interface())
+	return fmt.Sprint(fieldValue.Interface()), true
 }
-
@@ -0,0 +1,438 @@
+package lib
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// where older versions kept KEY=VALUE lines
+const legacyConfigPath = "~/.config/naurc"
+
+// written at the top of new config files
+const configHeader = `# nau configuration
+#
+# Fields left out keep their defaults. Run "nau config" to set them all or
+# "nau config <field> <value>" to set one, comments in this file are kept.
+`
+
+// config file given with --config, it wins over NAU_CONFIG
+var configOverride string
+
+// SetConfigPath makes nau read and write the config file at path
+func SetConfigPath(path string) {
+	configOverride = path
+}
+
+// ConfigDir returns the folder of the config file when none is given
+func ConfigDir() (string, error) {
+	return xdgDir("XDG_CONFIG_HOME", "~/.config")
+}
+
+// xdgDir returns the nau folder inside the base directory set in variable, or inside fallback
+// when it is not set. Relative paths are not valid there and are ignored, as the XDG spec says.
+func xdgDir(variable string, fallback string) (string, error) {
+	if dir := os.Getenv(variable); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "nau"), nil
+	}
+	dir, err := ExpandPath(fallback)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "nau"), nil
+}
+
+// configGiven returns the config file chosen with --config or NAU_CONFIG, empty if none was
+func configGiven() string {
+	if configOverride != "" {
+		return configOverride
+	}
+	return os.Getenv("NAU_CONFIG")
+}
+
+// ConfigPath returns the config file given with --config or NAU_CONFIG, else config.toml in ConfigDir
+func ConfigPath() (string, error) {
+	if given := configGiven(); given != "" {
+		path, err := ExpandPath(given)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Abs(path)
+	}
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.toml"), nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// readConfigFile reads the config file over the defaults, errors name the line at fault
+func readConfigFile(path string, config Config) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	text := string(data)
+	md, err := toml.Decode(text, &config)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %s", path, strings.TrimPrefix(err.Error(), "toml: "))
+	}
+	undecoded := md.Undecoded()
+	for _, key := range undecoded {
+		if isLeafKey(key, undecoded) {
+			return Config{}, lineError(path, text, key, "is not a config field")
+		}
+	}
+	for _, field := range CustomizableFields {
+		if md.IsDefined(strings.ToLower(field)) {
+			config.Origins[field] = OriginFile
+		}
+	}
+	if md.IsDefined("roots") {
+		config.Origins["PROJECTS_PATH"] = OriginFile
+	}
+	config.Launchers = lowerKeys(config.Launchers)
+	config.Per_template = lowerKeys(config.Per_template)
+	if err := validateConfig(path, text, md, &config); err != nil {
+		return Config{}, err
+	}
+	return config, nil
+}
+
+// validateConfig checks the values set in the file and builds the roots
+func validateConfig(path string, text string, md toml.MetaData, config *Config) error {
+	values := map[string]string{
+		"base_color":     config.Base_color,
+		"folder_pattern": config.Folder_pattern,
+		"code_length":    strconv.Itoa(config.Code_length),
+		"multiplexer":    config.Multiplexer,
+		"open":           config.Open,
+		"session":        config.Session,
+	}
+	for _, key := range []string{"base_color", "folder_pattern", "code_length", "multiplexer", "open", "session"} {
+		if !md.IsDefined(key) {
+			continue
+		}
+		if message := ValidateValue(strings.ToUpper(key), values[key]); message != "" {
+			return lineError(path, text, toml.Key{key}, message)
+		}
+	}
+	for name, command := range config.Launchers {
+		if message := validateLauncher(command); message != "" {
+			return lineError(path, text, toml.Key{"launchers", name}, message)
+		}
+	}
+	for name, options := range config.Per_template {
+		if message := validateLauncher(options.Open); options.Open != "" && message != "" {
+			return lineError(path, text, toml.Key{"templates", name, "open"}, message)
+		}
+		if message := validateLayout(options.Session); options.Session != "" && message != "" {
+			return lineError(path, text, toml.Key{"templates", name, "session"}, message)
+		}
+	}
+	if !md.IsDefined("roots") {
+		var err error
+		config.Roots, err = ParseRoots(config.Projects_path)
+		if err != nil {
+			return lineError(path, text, toml.Key{"projects_path"}, "• "+err.Error())
+		}
+		return nil
+	}
+	if md.IsDefined("projects_path") {
+		return lineError(path, text, toml.Key{"projects_path"}, "• Use either projects_path or [[roots]]")
+	}
+	for i := range config.Roots {
+		if config.Roots[i].Path == "" {
+			return fmt.Errorf("%s: every [[roots]] needs a path", path)
+		}
+		if config.Roots[i].Label == "" {
+			config.Roots[i].Label = filepath.Base(config.Roots[i].Path)
+		}
+	}
+	config.Projects_path = FormatRoots(config.Roots)
+	return nil
+}
+
+// isLeafKey tells whether no other key is inside key, so only the innermost unknown key is reported
+func isLeafKey(key toml.Key, keys []toml.Key) bool {
+	prefix := key.String() + "."
+	for _, other := range keys {
+		if strings.HasPrefix(other.String(), prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+func lowerKeys[V any](m map[string]V) map[string]V {
+	if m == nil {
+		return nil
+	}
+	lowered := make(map[string]V, len(m))
+	for key, value := range m {
+		lowered[strings.ToLower(key)] = value
+	}
+	return lowered
+}
+
+func lineError(path string, text string, key toml.Key, message string) error {
+	message = strings.TrimPrefix(message, "• ")
+	if line := findKeyLine(text, key); line > 0 {
+		return fmt.Errorf("%s: line %d: %s %s", path, line, key, message)
+	}
+	return fmt.Errorf("%s: %s %s", path, key, message)
+}
+
+// findKeyLine returns the line where key is set, 0 if it is not found
+func findKeyLine(text string, key toml.Key) int {
+	table := strings.Join(key[:len(key)-1], ".")
+	name := key[len(key)-1]
+	current := ""
+	for i, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			current = strings.Join(parseHeader(trimmed), ".")
+			continue
+		}
+		if current == table && strings.EqualFold(lineKey(trimmed), name) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// parseHeader returns the keys of a [table] or [[array]] line
+func parseHeader(line string) []string {
+	if end := strings.LastIndex(line, "]"); end >= 0 {
+		line = line[:end]
+	}
+	line = strings.Trim(line, "[] ")
+	var keys []string
+	for _, part := range strings.Split(line, ".") {
+		keys = append(keys, strings.ToLower(unquoteKey(part)))
+	}
+	return keys
+}
+
+// lineKey returns the key of a key = value line, empty for anything else
+func lineKey(line string) string {
+	if strings.HasPrefix(line, "#") {
+		return ""
+	}
+	parts := strings.SplitN(line, "=", 2)
+	if len(parts) != 2 {
+		return ""
+	}
+	return unquoteKey(parts[0])
+}
+
+func unquoteKey(key string) string {
+	return strings.Trim(strings.TrimSpace(key), `"'`)
+}
+
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func quoteKey(key string) string {
+	if bareKey.MatchString(key) {
+		return key
+	}
+	return strconv.Quote(key)
+}
+
+// fieldKey returns the table and key of a field of nau config, OPEN_PYTHON is open in [templates.python]
+func fieldKey(field string) ([]string, string) {
+	switch {
+	case field == openField || field == sessionField:
+		return nil, strings.ToLower(field)
+	case isLauncherField(field) && strings.HasPrefix(field, launcherFieldPrefix):
+		return []string{"launchers"}, strings.ToLower(strings.TrimPrefix(field, launcherFieldPrefix))
+	case isLauncherField(field) && strings.HasPrefix(field, templateOpenPrefix):
+		return []string{"templates", strings.ToLower(strings.TrimPrefix(field, templateOpenPrefix))}, "open"
+	case isSessionField(field):
+		return []string{"templates", strings.ToLower(strings.TrimPrefix(field, templateSessionPrefix))}, "session"
+	}
+	return nil, strings.ToLower(field)
+}
+
+// encodeValue writes a value as TOML, numbers and booleans for the fields that are
+func encodeValue(field string, value string) (string, error) {
+	switch field {
+	case "CODE_LENGTH":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return "", fmt.Errorf("Invalid CODE_LENGTH: %s", value)
+		}
+		return strconv.Itoa(n), nil
+	case "STAY_OPEN":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("Invalid STAY_OPEN: %s", value)
+		}
+		return strconv.FormatBool(b), nil
+	}
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(map[string]string{"v": value}); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(strings.TrimPrefix(buf.String(), "v = ")), nil
+}
+
+// setConfigField sets a field in the text of a config file, leaving the rest of it as it was
+func setConfigField(text string, field string, value string) (string, error) {
+	field = strings.ToUpper(field)
+	table, key := fieldKey(field)
+	encoded, err := encodeValue(field, value)
+	if err != nil {
+		return "", err
+	}
+	text = setKey(text, table, key, encoded)
+	//never write a file that does not read
+	var config Config
+	md, err := toml.Decode(text, &config)
+	if err != nil {
+		return "", fmt.Errorf("Could not set %s: %s", field, err)
+	}
+	if field == "PROJECTS_PATH" && md.IsDefined("roots") {
+		return "", fmt.Errorf("The projects paths are set by the [[roots]] tables of the config file")
+	}
+	return text, nil
+}
+
+// setKey replaces the value of key in table keeping its comment, or adds it at the end of the table
+func setKey(text string, table []string, key string, value string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	want := strings.Join(table, ".")
+	current := ""
+	found := want == ""
+	last := -1 //last line of the table with a key or its header
+	firstHeader := -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			if firstHeader < 0 {
+				firstHeader = i
+			}
+			current = strings.Join(parseHeader(trimmed), ".")
+			if current == want {
+				found = true
+				last = i
+			}
+			continue
+		}
+		if current != want {
+			continue
+		}
+		if strings.EqualFold(lineKey(trimmed), key) {
+			lines[i] = replaceValue(line, value)
+			return strings.Join(lines, "\n") + "\n"
+		}
+		if lineKey(trimmed) != "" {
+			last = i
+		}
+	}
+	entry := quoteKey(key) + " = " + value
+	switch {
+	case !found:
+		var header []string
+		for _, part := range table {
+			header = append(header, quoteKey(part))
+		}
+		lines = append(lines, "", "["+strings.Join(header, ".")+"]", entry)
+	case last >= 0:
+		lines = insertLine(lines, last+1, entry)
+	case firstHeader >= 0:
+		//top level keys go before the first table
+		lines = insertLine(lines, firstHeader, entry, "")
+	default:
+		//keep a gap after the comments at the top
+		if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) != "" {
+			lines = append(lines, "")
+		}
+		lines = append(lines, entry)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func insertLine(lines []string, at int, inserted ...string) []string {
+	result := append([]string{}, lines[:at]...)
+	result = append(result, inserted...)
+	return append(result, lines[at:]...)
+}
+
+// replaceValue swaps the value of a key = value line, keeping the indent and a trailing comment
+func replaceValue(line string, value string) string {
+	eq := strings.Index(line, "=")
+	return strings.TrimRight(line[:eq], " \t") + " = " + value + trailingComment(line[eq+1:])
+}
+
+func trailingComment(s string) string {
+	var quote rune
+	escaped := false
+	for i, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case quote == '"' && r == '\\':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return " " + s[i:]
+		}
+	}
+	return ""
+}
+
+func writeConfigFile(path string, text string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(text), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// migrateConfig writes the fields of a naurc file into a new config file
+func migrateConfig(legacyPath string, path string) error {
+	data, err := os.ReadFile(legacyPath)
+	if err != nil {
+		return err
+	}
+	text := configHeader
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("line %d is not FIELD=value", i+1)
+		}
+		field := strings.ToUpper(strings.TrimSpace(parts[0]))
+		if !isCustomizableField(field) && field != "WEBSITE" {
+			return fmt.Errorf("line %d: unknown field %s", i+1, field)
+		}
+		text, err = setConfigField(text, field, strings.TrimSpace(parts[1]))
+		if err != nil {
+			return fmt.Errorf("line %d: %s", i+1, err)
+		}
+	}
+	return writeConfigFile(path, text)
+}
@@ -0,0 +1,185 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetConfigField(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		field string
+		value string
+		want  string
+	}{
+		{
+			name:  "replaces a value keeping its comment",
+			text:  "# mine\nbase_color = \"#111111\" # dark\ncode_length = 3\n",
+			field: "base_color",
+			value: "#222222",
+			want:  "# mine\nbase_color = \"#222222\" # dark\ncode_length = 3\n",
+		},
+		{
+			name:  "keeps a # inside a string",
+			text:  "author = \"A # B\" # name\n",
+			field: "AUTHOR",
+			value: "C",
+			want:  "author = \"C\" # name\n",
+		},
+		{
+			name:  "writes numbers and booleans bare",
+			text:  "code_length = 3\n",
+			field: "CODE_LENGTH",
+			value: "4",
+			want:  "code_length = 4\n",
+		},
+		{
+			name:  "adds a field after the comments at the top",
+			text:  "# nau configuration\n",
+			field: "STAY_OPEN",
+			value: "true",
+			want:  "# nau configuration\n\nstay_open = true\n",
+		},
+		{
+			name:  "adds a top level field before the first table",
+			text:  "author = \"A\"\n\n[launchers]\nvim = \"vim .\"\n",
+			field: "EMAIL",
+			value: "a@b.c",
+			want:  "author = \"A\"\nemail = \"a@b.c\"\n\n[launchers]\nvim = \"vim .\"\n",
+		},
+		{
+			name:  "adds a launcher to its table",
+			text:  "[launchers]\nvim = \"vim .\"\n\n[templates.python]\nopen = \"code .\"\n",
+			field: "LAUNCHER_CODE",
+			value: "code {{.Path}}",
+			want:  "[launchers]\nvim = \"vim .\"\ncode = \"code {{.Path}}\"\n\n[templates.python]\nopen = \"code .\"\n",
+		},
+		{
+			name:  "replaces the launcher of a template",
+			text:  "[templates.python]\nopen = \"code .\" # editor\n",
+			field: "OPEN_PYTHON",
+			value: "vim",
+			want:  "[templates.python]\nopen = \"vim\" # editor\n",
+		},
+		{
+			name:  "creates the table of a template",
+			text:  "author = \"A\"\n",
+			field: "SESSION_RUST",
+			value: "editor:@open",
+			want:  "author = \"A\"\n\n[templates.rust]\nsession = \"editor:@open\"\n",
+		},
+		{
+			name:  "quotes keys that are not bare",
+			text:  "# mine\n",
+			field: "LAUNCHER_MY.EDITOR",
+			value: "ed",
+			want:  "# mine\n\n[launchers]\n\"my.editor\" = \"ed\"\n",
+		},
+		{
+			name:  "escapes strings",
+			text:  "# mine\n",
+			field: "FOLDER_PATTERN",
+			value: `{{.Code}}"\`,
+			want:  "# mine\n\nfolder_pattern = \"{{.Code}}\\\"\\\\\"\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := setConfigField(test.text, test.field, test.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("setConfigField(%q, %s, %q) =\n%s\nwant\n%s", test.text, test.field, test.value, got, test.want)
+			}
+		})
+	}
+}
+
+func TestSetConfigFieldErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		field string
+		value string
+	}{
+		{"projects path with roots", "[[roots]]\npath = \"~/work\"\n", "PROJECTS_PATH", "~/code"},
+		{"code length that is not a number", "", "CODE_LENGTH", "three"},
+		{"stay open that is not a boolean", "", "STAY_OPEN", "sometimes"},
+		{"file that does not read", "author = \n", "EMAIL", "a@b.c"},
+	}
+	for _, test := range tests {
+		if _, err := setConfigField(test.text, test.field, test.value); err == nil {
+			t.Errorf("%s: setConfigField(%q, %s, %q) did not fail", test.name, test.text, test.field, test.value)
+		}
+	}
+}
+
+func TestMigrateConfig(t *testing.T) {
+	projects := t.TempDir()
+	tests := []struct {
+		name    string
+		naurc   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "fields and comments",
+			naurc: "# old config\nAUTHOR=Ada Lovelace\n\nemail = ada@example.com\nCODE_LENGTH=2\n" +
+				"PROJECTS_PATH=" + projects + "\nWEBSITE=https://example.com\n",
+			want: map[string]string{
+				"AUTHOR":        "Ada Lovelace",
+				"EMAIL":         "ada@example.com",
+				"CODE_LENGTH":   "2",
+				"PROJECTS_PATH": projects,
+			},
+		},
+		{
+			name:  "values with = in them",
+			naurc: "FOLDER_PATTERN={{.Code}}={{.Hyphen}}\n",
+			want:  map[string]string{"FOLDER_PATTERN": "{{.Code}}={{.Hyphen}}"},
+		},
+		{name: "line without a value", naurc: "AUTHOR\n", wantErr: true},
+		{name: "unknown field", naurc: "COLOUR=red\n", wantErr: true},
+		{name: "invalid value", naurc: "STAY_OPEN=maybe\n", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			legacy := filepath.Join(dir, "naurc")
+			path := filepath.Join(dir, "nau", "config.toml")
+			if err := os.WriteFile(legacy, []byte(test.naurc), 0644); err != nil {
+				t.Fatal(err)
+			}
+			err := migrateConfig(legacy, path)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("migrateConfig error = %v, want error %v", err, test.wantErr)
+			}
+			if err != nil {
+				if fileExists(path) {
+					t.Error("a failed migration wrote the config file")
+				}
+				return
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(string(data), configHeader) {
+				t.Errorf("migrated config does not start with the header:\n%s", data)
+			}
+			config, err := readConfigFile(path, Config{Projects_path: projects, Origins: make(map[string]string)})
+			if err != nil {
+				t.Fatalf("migrated config does not read: %s\n%s", err, data)
+			}
+			for field, value := range test.want {
+				if got, _ := FieldValue(config, field); got != value {
+					t.Errorf("%s = %q, want %q", field, got, value)
+				}
+			}
+		})
+	}
+}
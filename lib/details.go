@@ -0,0 +1,111 @@
+package lib
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var readmeNames = []string{"README.md", "README", "README.txt", "README.rst", "readme.md"}
+
+// ReadmeParagraph returns the first paragraph of text of the project README
+func ReadmeParagraph(projectPath string) string {
+	for _, name := range readmeNames {
+		file, err := os.Open(filepath.Join(projectPath, name))
+		if err != nil {
+			continue
+		}
+		defer file.Close()
+		var paragraph []string
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			//skip titles, badges and html until the first real text
+			if len(paragraph) == 0 && (line == "" || strings.HasPrefix(line, "#") ||
+				strings.HasPrefix(line, "<") || strings.HasPrefix(line, "[!") ||
+				strings.HasPrefix(line, "!") || strings.HasPrefix(line, "=") || strings.HasPrefix(line, "-")) {
+				continue
+			}
+			if line == "" {
+				break
+			}
+			paragraph = append(paragraph, line)
+		}
+		return strings.Join(paragraph, " ")
+	}
+	return ""
+}
+
+// limits of the walk that measures a project
+const (
+	sizeMaxDepth   = 8
+	sizeMaxEntries = 20000
+)
+
+// DiskSize adds up the size of the files of the project, leaving out dependencies, build output and
+// hidden folders such as .git. Large projects are only partly measured and complete is false.
+func DiskSize(projectPath string) (size int64, complete bool) {
+	complete = walkProject(projectPath, sizeMaxDepth, sizeMaxEntries, func(d fs.DirEntry) {
+		if d.IsDir() {
+			return
+		}
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+		}
+	})
+	return size, complete
+}
+
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// RelativeTime formats a time as "3 days ago"
+func RelativeTime(t time.Time) string {
+	if t.IsZero() {
+		return "unknown"
+	}
+	d := time.Since(t)
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s ago", unit)
+		}
+		return fmt.Sprintf("%d %ss ago", n, unit)
+	}
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return plural(int(d.Minutes()), "minute")
+	case d < 24*time.Hour:
+		return plural(int(d.Hours()), "hour")
+	case d < 30*24*time.Hour:
+		return plural(int(d.Hours()/24), "day")
+	case d < 365*24*time.Hour:
+		return plural(int(d.Hours()/24/30), "month")
+	default:
+		return plural(int(d.Hours()/24/365), "year")
+	}
+}
+
+// ShortPath replaces the home directory with ~
+func ShortPath(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || !strings.HasPrefix(path, home) {
+		return path
+	}
+	return "~" + strings.TrimPrefix(path, home)
+}
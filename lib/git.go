@@ -0,0 +1,115 @@
+package lib
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/muesli/reflow/truncate"
+)
+
+// state of the repository of a project
+type GitStatus struct {
+	IsRepo    bool
+	Branch    string
+	Upstream  string
+	Ahead     int
+	Behind    int
+	Changed   int //tracked files that are staged or modified
+	Untracked int
+}
+
+func (s GitStatus) Dirty() bool {
+	return s.Changed > 0 || s.Untracked > 0
+}
+
+// Badge is the short form of the status shown next to the project name
+func (s GitStatus) Badge() string {
+	if !s.IsRepo {
+		return ""
+	}
+	badge := truncate.StringWithTail(s.Branch, 12, "…")
+	if s.Changed > 0 {
+		badge += " ●"
+	}
+	if s.Untracked > 0 {
+		badge += " ?"
+	}
+	if s.Ahead > 0 {
+		badge += fmt.Sprintf(" ↑%d", s.Ahead)
+	}
+	if s.Behind > 0 {
+		badge += fmt.Sprintf(" ↓%d", s.Behind)
+	}
+	return badge
+}
+
+// Summary is the long form of the status
+func (s GitStatus) Summary() []string {
+	if !s.IsRepo {
+		return []string{"not a repository"}
+	}
+	lines := []string{"on " + s.Branch}
+	if s.Dirty() {
+		lines = append(lines, fmt.Sprintf("%d changed", s.Changed), fmt.Sprintf("%d untracked", s.Untracked))
+	} else {
+		lines = append(lines, "clean")
+	}
+	if s.Upstream == "" {
+		lines = append(lines, "no upstream")
+	} else {
+		lines = append(lines, fmt.Sprintf("↑%d ↓%d %s", s.Ahead, s.Behind, s.Upstream))
+	}
+	return lines
+}
+
+// GetGitStatus runs git status on a project. Projects that are not repositories have an empty status.
+func GetGitStatus(path string) (GitStatus, error) {
+	if _, ok := findGitDir(path); !ok {
+		return GitStatus{}, nil
+	}
+	cmd := exec.Command("git", "status", "--porcelain=v2", "--branch")
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return GitStatus{}, err
+	}
+	return parseGitStatus(output), nil
+}
+
+func parseGitStatus(output []byte) GitStatus {
+	status := GitStatus{IsRepo: true}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "# branch.head "):
+			status.Branch = strings.TrimPrefix(line, "# branch.head ")
+		case strings.HasPrefix(line, "# branch.upstream "):
+			status.Upstream = strings.TrimPrefix(line, "# branch.upstream ")
+		case strings.HasPrefix(line, "# branch.ab "):
+			fmt.Sscanf(strings.TrimPrefix(line, "# branch.ab "), "+%d -%d", &status.Ahead, &status.Behind)
+		case strings.HasPrefix(line, "? "):
+			status.Untracked++
+		case strings.HasPrefix(line, "1 "), strings.HasPrefix(line, "2 "), strings.HasPrefix(line, "u "):
+			status.Changed++
+		}
+	}
+	return status
+}
+
+// GitLog returns the last n commits of a project, one line each
+func GitLog(path string, n int) []string {
+	if _, ok := findGitDir(path); !ok {
+		return nil
+	}
+	cmd := exec.Command("git", "log", fmt.Sprintf("-n%d", n), "--format=%h %s (%cr)")
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil || len(bytes.TrimSpace(output)) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSpace(string(output)), "\n")
+}
@@ -0,0 +1,144 @@
+package lib
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/sahilm/fuzzy"
+)
+
+// how often and how recently a project was opened
+type Visit struct {
+	Count int
+	Last  time.Time
+}
+
+// visits of each project by path
+type History map[string]Visit
+
+func historyPath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "history.json"), nil
+}
+
+// LoadHistory returns the projects opened so far, empty when there is no history
+func LoadHistory() History {
+	history := make(History)
+	path, err := historyPath()
+	if err != nil {
+		return history
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return history
+	}
+	json.Unmarshal(data, &history)
+	return history
+}
+
+func saveHistory(history History) error {
+	path, err := historyPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(history)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// RecordOpen counts one more opening of a project
+func RecordOpen(projectPath string) error {
+	history := LoadHistory()
+	visit := history[projectPath]
+	visit.Count++
+	visit.Last = time.Now()
+	history[projectPath] = visit
+	return saveHistory(history)
+}
+
+// moveHistory keeps the visits of a project that changed folder
+func moveHistory(oldPath string, newPath string) {
+	history := LoadHistory()
+	visit, ok := history[oldPath]
+	if !ok {
+		return
+	}
+	delete(history, oldPath)
+	history[newPath] = visit
+	saveHistory(history)
+}
+
+// Frecency weighs how often a project was opened by how recently it was
+func (h History) Frecency(projectPath string, now time.Time) float64 {
+	visit, ok := h[projectPath]
+	if !ok {
+		return 0
+	}
+	age := now.Sub(visit.Last)
+	switch {
+	case age < time.Hour:
+		return float64(visit.Count) * 4
+	case age < 24*time.Hour:
+		return float64(visit.Count) * 2
+	case age < 7*24*time.Hour:
+		return float64(visit.Count) / 2
+	default:
+		return float64(visit.Count) / 4
+	}
+}
+
+// MostFrecent returns the project opened most often lately, or the most recently modified without history
+func (h History) MostFrecent(projects []Project) (Project, bool) {
+	if len(projects) == 0 {
+		return Project{}, false
+	}
+	now := time.Now()
+	best := projects[0]
+	for _, project := range projects[1:] {
+		a, b := h.Frecency(project.Path, now), h.Frecency(best.Path, now)
+		if a > b || (a == b && project.Timestamp.After(best.Timestamp)) {
+			best = project
+		}
+	}
+	return best, true
+}
+
+// a project opened every day gains about 20, the gap between a loose and a tight fuzzy match
+const frecencyWeight = 5
+
+// a project with how well it matched a query
+type Ranked struct {
+	Project Project
+	Score   float64
+}
+
+// Rank scores fuzzy matches of projects adding the frecency of each one, best first
+func (h History) Rank(projects []Project, matches fuzzy.Matches) []Ranked {
+	now := time.Now()
+	var ranked []Ranked
+	for _, match := range matches {
+		project := projects[match.Index]
+		score := float64(match.Score) + frecencyWeight*math.Log1p(h.Frecency(project.Path, now))
+		ranked = append(ranked, Ranked{Project: project, Score: score})
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+	return ranked
+}
@@ -0,0 +1,152 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// the index is rebuilt at least this often so timestamps do not go stale
+const indexMaxAge = time.Hour
+
+// cached result of scanning the project roots
+type projectIndex struct {
+	Key      string
+	Created  time.Time
+	Dirs     map[string]time.Time //modification time of every root and group folder
+	Metadata map[string]time.Time //modification time of the metadata of every project, zero without one
+	Projects []Project
+}
+
+// CacheDir returns the folder where nau keeps files that can be rebuilt
+func CacheDir() (string, error) {
+	return xdgDir("XDG_CACHE_HOME", "~/.cache")
+}
+
+func indexPath() (string, error) {
+	dir, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "index.json"), nil
+}
+
+// identifies the configuration the index was built with
+func indexKey(config Config) string {
+	templates := make([]string, 0, len(config.Templates))
+	for name, color := range config.Templates {
+		templates = append(templates, name+color)
+	}
+	sort.Strings(templates)
+	return strings.Join([]string{
+		version,
+		config.Folder_pattern,
+		config.Base_color,
+		fmt.Sprintf("%v", config.Roots),
+		strings.Join(templates, ","),
+	}, "|")
+}
+
+func loadIndex(config Config) (projectIndex, bool) {
+	var index projectIndex
+	path, err := indexPath()
+	if err != nil {
+		return index, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return index, false
+	}
+	if err := json.Unmarshal(data, &index); err != nil {
+		return index, false
+	}
+	if index.Key != indexKey(config) || time.Since(index.Created) > indexMaxAge || index.Metadata == nil {
+		return index, false
+	}
+	//adding or removing a project changes the folder it is in
+	for dir, modTime := range index.Dirs {
+		info, err := os.Stat(dir)
+		//a zero time is a root that was missing, it stays valid until the root is back
+		if modTime.IsZero() && os.IsNotExist(err) {
+			continue
+		}
+		if err != nil || !info.ModTime().Equal(modTime) {
+			return index, false
+		}
+	}
+	return index, true
+}
+
+func saveIndex(index projectIndex) error {
+	path, err := indexPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	//write and rename so concurrent runs never read half an index
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Reindex scans all project roots and stores the result in the index
+func Reindex(config Config) ([]Project, error) {
+	dirs := make(map[string]time.Time)
+	projects, err := scanRoots(config, dirs)
+	if err != nil {
+		return nil, err
+	}
+	fillTimestamps(projects)
+	metadata := make(map[string]time.Time)
+	refreshMetadata(projects, metadata)
+	//the index is only a cache, failing to write it is not an error
+	saveIndex(projectIndex{
+		Key:      indexKey(config),
+		Created:  time.Now(),
+		Dirs:     dirs,
+		Metadata: metadata,
+		Projects: projects,
+	})
+	return projects, nil
+}
+
+// forEachProject calls work with the index of every project using a pool of workers
+func forEachProject(projects []Project, work func(i int)) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.NumCPU()*4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				work(i)
+			}
+		}()
+	}
+	for i := range projects {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// reads the timestamps of all projects
+func fillTimestamps(projects []Project) {
+	forEachProject(projects, func(i int) {
+		projects[i].Timestamp, _ = getDirectoryTimestamp(projects[i].Path)
+	})
+}
@@ -1,8 +1,6 @@
 package lib
 
 import (
-	"fmt"
-	"io/ioutil"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -20,7 +18,12 @@ type Project struct {
 	Lang         string
 	Color        string
 	Path         string
+	Root         string //label of the root the project is in
+	Group        string //group folders between the root and the project
     Timestamp    time.Time //Time the rpoject was last modified
+	Tags         []string
+	Pinned       bool
+	Archived     bool //Path is the compressed archive
 }
 
 func ToHyphenName(s string) string {
@@ -74,10 +77,14 @@ func ToDisplayName(s string) string {
 	return strings.Join(words, " ")
 }
 
-func discombobulate(s string) (string, string, string, string, string) {
-	code := s[:3]
-	name := s[4:]
-	return code, ToDunderName(name), ToFolderName(name), ToHyphenName(name), ToDisplayName(name)
+func discombobulate(pattern string, s string) (string, string, string, string, string) {
+	code, name, ok := ParseFolderName(pattern, s)
+	if !ok {
+		//folders not following the pattern are kept with their full name
+		code, name = "", s
+	}
+	dunder := ToDunderName(name)
+	return code, dunder, ToFolderName(dunder), ToHyphenName(dunder), ToDisplayName(name)
 }
 
 func contains(color_map map[string]string, key string) bool {
@@ -110,40 +117,11 @@ func validEntry(entry os.DirEntry) bool {
 	return true
 }
 func countProjects(config Config) (int, error) {
-	projectsPath, err := ExpandPath(config.Projects_path)
-	if err != nil {
-		return 0, err
-	}
-	return countSubdirectories(projectsPath, config.Templates)
-}
-func countSubdirectories(path string, templates map[string]string) (int, error) {
-	// Check if the path is a directory
-	fileInfo, err := ioutil.ReadDir(path)
+	projects, err := GetProjects(config)
 	if err != nil {
 		return 0, err
 	}
-	count := 0
-
-	for _, file := range fileInfo {
-		//if it is not a direcotory
-		if !file.IsDir() {
-			continue
-		}
-		//if it is named after a template
-		if _, ok := templates[file.Name()]; ok {
-			// Recursively count subdirectories of template subdirectory
-			subdirPath := filepath.Join(path, file.Name())
-			subdirCount, err := countSubdirectories(subdirPath, templates)
-			if err != nil {
-				return 0, err
-			}
-			count += subdirCount
-		} else {
-
-			count++
-		}
-	}
-	return count, nil
+	return len(projects), nil
 }
 
 // get all themes
@@ -181,106 +159,54 @@ func loadTemplatesColorMap(dirPath string) (map[string]string, error) {
 	return nameColorMap, nil
 }
 
-// utils get all projects
-func getThemedProjects(path string, lang string, color string) ([]Project, error) {
-	var themedProjects []Project
-	subentries, err := os.ReadDir(path + "/" + lang)
-	if err != nil {
-		return themedProjects, err
-	}
-	for _, subentry := range subentries {
-		if !validEntry(subentry) {
-			continue
-		}
-		code, name, folder_name, repo_name, display_name := discombobulate(subentry.Name())
-
-		timestamp, _ := getDirectoryTimestamp(path + "/" + lang + "/" + subentry.Name())
-		project := Project{
-			Name:         name,
-			Folder_name:  folder_name,
-			Repo_name:    repo_name,
-			Display_Name: display_name,
-			Code:         code,
-			Lang:         lang,
-			Color:        color,
-			Path:         path + "/" + lang + "/" + subentry.Name(),
-			Timestamp:    timestamp,
+// GetProjects returns all projects, from the index when it is still valid
+func GetProjects(config Config) ([]Project, error) {
+	if index, ok := loadIndex(config); ok {
+		if refreshMetadata(index.Projects, index.Metadata) {
+			saveIndex(index)
 		}
-		themedProjects = append(themedProjects, project)
+		return index.Projects, nil
 	}
-	return themedProjects, nil
-
-}
-
-func getDirectoryTimestamp(dirPath string) (time.Time, error) {
-	// Check if the directory exists
-	fileInfo, err := os.Stat(dirPath)
-	if err != nil {
-		return time.Time{}, err
-	}
-
-	// If the directory contains a .git file, return the modification time of that file
-	gitFilePath := dirPath + "/.git"
-	gitFileInfo, err := os.Stat(gitFilePath)
-	if err == nil && !gitFileInfo.IsDir() {
-		return gitFileInfo.ModTime(), nil
-	}
-
-	// If the directory does not contain a .git file, return the creation time of the directory
-	if fileInfo.IsDir() {
-		return fileInfo.ModTime(), nil
-	}
-
-	// If the directory exists but is not a directory, return an error
-    return time.Time{}, fmt.Errorf("Could not read timestamp from %s", dirPath)
+	return Reindex(config)
 }
 
-func GetProjects(config Config) ([]Project, error) {
-	var projectNames []Project
+// suffix of the files made by nau archive
+const archiveSuffix = ".tar.gzip"
 
-	//convert the raw path
-	projectPath, err := ExpandPath(config.Projects_path)
+// GetArchivedProjects lists the projects in the archives folder
+func GetArchivedProjects(config Config) ([]Project, error) {
+	archivesPath, err := ExpandPath(config.Archives_path)
 	if err != nil {
 		return nil, err
 	}
-	//read projects directory
-	entries, err := os.ReadDir(projectPath)
+	entries, err := os.ReadDir(archivesPath)
 	if err != nil {
 		return nil, err
 	}
-
+	var projects []Project
 	for _, entry := range entries {
-		if !validEntry(entry) {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), archiveSuffix) {
 			continue
 		}
-		if contains(config.Templates, entry.Name()) {
-			themedProjects, err := getThemedProjects(projectPath, entry.Name(), config.Templates[entry.Name()])
-            if err != nil{
-                return projectNames, err
-            }
-			projectNames = append(projectNames, themedProjects...)
-		} else {
-			code, name, folder_name, repo_name, display_name := discombobulate(entry.Name())
-			timestamp, err := getDirectoryTimestamp(projectPath + "/" + entry.Name())
-			if err != nil {
-				return nil, err
-			}
-			project := Project{
-				Name:         name,
-				Folder_name:  folder_name,
-				Repo_name:    repo_name,
-				Display_Name: display_name,
-				Code:         code,
-				Lang:         "Mixed",
-				Color:        config.Base_color,
-				Path:         projectPath + "/" + entry.Name(),
-				Timestamp:    timestamp,
-			}
-			projectNames = append(projectNames, project)
+		info, err := entry.Info()
+		if err != nil {
+			continue
 		}
-
-	}
-	return projectNames, nil
+		code, name, folder_name, repo_name, display_name := discombobulate(config.Folder_pattern, strings.TrimSuffix(entry.Name(), archiveSuffix))
+		projects = append(projects, Project{
+			Name:         name,
+			Folder_name:  folder_name,
+			Repo_name:    repo_name,
+			Display_Name: display_name,
+			Code:         code,
+			Lang:         "Archived",
+			Color:        config.Base_color,
+			Path:         filepath.Join(archivesPath, entry.Name()),
+			Timestamp:    info.ModTime(),
+			Archived:     true,
+		})
+	}
+	return projects, nil
 }
 
 // hidden function
@@ -0,0 +1,182 @@
+package lib
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/mattn/go-shellwords"
+)
+
+// config fields that define launchers, OPEN_<TEMPLATE> and LAUNCHER_<NAME> take a name after the prefix
+const (
+	openField           = "OPEN"
+	templateOpenPrefix  = "OPEN_"
+	launcherFieldPrefix = "LAUNCHER_"
+)
+
+// launcher that runs the editor alone, always available to --with
+const EditorLauncher = "editor"
+
+// what the placeholders of a launcher refer to, as in code {{.Path}}
+type LaunchData struct {
+	Path   string
+	Name   string
+	Code   string
+	Lang   string
+	Editor string
+}
+
+func isLauncherField(field string) bool {
+	return field == openField ||
+		(strings.HasPrefix(field, templateOpenPrefix) && len(field) > len(templateOpenPrefix)) ||
+		(strings.HasPrefix(field, launcherFieldPrefix) && len(field) > len(launcherFieldPrefix))
+}
+
+// LauncherField returns the command set for a launcher field such as LAUNCHER_CODE
+func (c Config) LauncherField(field string) (string, bool) {
+	field = strings.ToUpper(field)
+	switch {
+	case !isLauncherField(field):
+		return "", false
+	case field == openField:
+		return c.Open, true
+	case strings.HasPrefix(field, templateOpenPrefix):
+		return c.Per_template[strings.ToLower(strings.TrimPrefix(field, templateOpenPrefix))].Open, true
+	default:
+		return c.Launchers[strings.ToLower(strings.TrimPrefix(field, launcherFieldPrefix))], true
+	}
+}
+
+// LauncherNames lists the launchers --with accepts, sorted
+func (c Config) LauncherNames() []string {
+	names := []string{EditorLauncher}
+	for name := range c.Launchers {
+		if name != EditorLauncher {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LauncherCommand returns the command that opens a project. A named launcher is looked up in the
+// project and then in the config, otherwise the project's own, its template's, the global one and
+// at last the editor are tried in this order.
+func (c Config) LauncherCommand(project Project, name string) (string, error) {
+	meta, err := ReadMetadata(project.Path)
+	if err != nil {
+		return "", err
+	}
+	if name != "" {
+		name = strings.ToLower(name)
+		if command, ok := meta.Launchers[name]; ok {
+			return command, checkTrusted(project, meta, command)
+		}
+		if command, ok := c.Launchers[name]; ok {
+			return command, nil
+		}
+		if name == EditorLauncher {
+			return c.Editor, nil
+		}
+		return "", fmt.Errorf("Unknown launcher %s, use one of: %s", name, strings.Join(c.LauncherNames(), ", "))
+	}
+	if meta.Open != "" {
+		return meta.Open, checkTrusted(project, meta, meta.Open)
+	}
+	for _, command := range []string{c.Per_template[strings.ToLower(project.Lang)].Open, c.Open} {
+		if command != "" {
+			return command, nil
+		}
+	}
+	return c.Editor, nil
+}
+
+// checkTrusted refuses a launcher from the metadata of a project until the user trusts it
+func checkTrusted(project Project, meta Metadata, command string) error {
+	if launchersTrusted(project.Path, meta) {
+		return nil
+	}
+	return fmt.Errorf("%s sets the launcher %q in %s, check it and run nau open --trust to allow it", ShortPath(project.Path), command, MetadataFile)
+}
+
+// placeholder that stands for the editor with its arguments
+const editorPlaceholder = "{{.Editor}}"
+
+// SplitCommand splits a command into arguments following the quoting rules of the shell
+func SplitCommand(command string) ([]string, error) {
+	args, err := shellwords.Parse(command)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid command %q: %s", command, err)
+	}
+	return args, nil
+}
+
+// LaunchArgs splits a launcher into arguments and fills in the placeholders of each one.
+// An argument that is only {{.Editor}} becomes the editor and its own arguments.
+func LaunchArgs(command string, project Project, editor string) ([]string, error) {
+	data := LaunchData{
+		Path:   project.Path,
+		Name:   project.Name,
+		Code:   project.Code,
+		Lang:   project.Lang,
+		Editor: editor,
+	}
+	//placeholders written with spaces, as in {{ .Path }}, stay inside a single argument
+	words, err := SplitCommand(placeholderRegex.ReplaceAllString(command, "{{.$1}}"))
+	if err != nil {
+		return nil, err
+	}
+	var args []string
+	for _, word := range words {
+		if strings.Count(word, "{{") != strings.Count(word, "}}") {
+			return nil, fmt.Errorf("Invalid launcher %q: only placeholders such as {{.Path}} can be used, write other actions without spaces", command)
+		}
+		if word == editorPlaceholder {
+			editorArgs, err := SplitCommand(editor)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, editorArgs...)
+			continue
+		}
+		tmpl, err := template.New("launcher").Option("missingkey=error").Parse(word)
+		if err != nil {
+			return nil, err
+		}
+		var arg strings.Builder
+		if err := tmpl.Execute(&arg, data); err != nil {
+			return nil, fmt.Errorf("Invalid launcher %q: %s", command, err)
+		}
+		args = append(args, arg.String())
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("No command to open %s", project.Display_Name)
+	}
+	return args, nil
+}
+
+func validateLauncher(command string) string {
+	if strings.TrimSpace(command) == "" {
+		return "• Launcher cannot be empty"
+	}
+	_, err := LaunchArgs(command, Project{}, "")
+	if err != nil {
+		return "• Not a valid command, placeholders are {{.Path}}, {{.Name}}, {{.Code}}, {{.Lang}} and {{.Editor}}"
+	}
+	return ""
+}
+
+// validateEditor checks that the editor and its arguments can be run from here
+func validateEditor(editor string) string {
+	args, err := SplitCommand(editor)
+	if err != nil || len(args) == 0 {
+		return "• Not a valid command"
+	}
+	if _, err := exec.LookPath(args[0]); err != nil {
+		return "• " + args[0] + " was not found on PATH"
+	}
+	return ""
+}
@@ -0,0 +1,164 @@
+package lib
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CheckUnique fails if another project already uses the name or the code
+func CheckUnique(projects []Project, name string, code string, except string) error {
+	repo_name := ToHyphenName(ToDunderName(name))
+	for _, project := range projects {
+		if project.Path == except {
+			continue
+		}
+		if project.Repo_name == repo_name {
+			return fmt.Errorf("Name already in use by %s", ShortPath(project.Path))
+		}
+		if code != "" && project.Code == strings.ToUpper(code) {
+			return fmt.Errorf("Code already in use by %s", ShortPath(project.Path))
+		}
+	}
+	return nil
+}
+
+// ValidateCode checks a code against the configured length
+func ValidateCode(config Config, code string) error {
+	if config.Code_length == 0 {
+		if code != "" {
+			return fmt.Errorf("Projects do not have codes")
+		}
+		return nil
+	}
+	if len(code) != config.Code_length {
+		return fmt.Errorf("Code needs %d characters", config.Code_length)
+	}
+	for _, c := range strings.ToUpper(code) {
+		if !isCodeRune(c) {
+			return fmt.Errorf("Code can only have letters and digits")
+		}
+	}
+	return nil
+}
+
+func siblingPath(config Config, project Project, name string, code string) (string, error) {
+	if strings.TrimSpace(name) == "" {
+		return "", fmt.Errorf("Name cannot be empty")
+	}
+	if err := ValidateCode(config, code); err != nil {
+		return "", err
+	}
+	folder_name, err := FormatFolderName(config.Folder_pattern, code, name)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(filepath.Dir(project.Path), folder_name)
+	//keeping the name and the code leaves the project where it is
+	if path == project.Path {
+		return path, nil
+	}
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("%s already exists", ShortPath(path))
+	}
+	return path, nil
+}
+
+// RenameProject renames the folder of a project and returns its new path
+func RenameProject(config Config, project Project, name string, code string) (string, error) {
+	path, err := siblingPath(config, project, name, code)
+	if err != nil || path == project.Path {
+		return path, err
+	}
+	if err := os.Rename(project.Path, path); err != nil {
+		return "", err
+	}
+	moveHistory(project.Path, path)
+	return path, nil
+}
+
+// DuplicateProject copies a project next to it under a new name and returns the path of the copy
+func DuplicateProject(config Config, project Project, name string, code string) (string, error) {
+	path, err := siblingPath(config, project, name, code)
+	if err != nil {
+		return "", err
+	}
+	if path == project.Path {
+		return "", fmt.Errorf("%s already exists", ShortPath(path))
+	}
+	return path, CopyTree(project.Path, path)
+}
+
+// MoveProject moves a project to the group of template inside the same root
+func MoveProject(config Config, project Project, template string) (string, error) {
+	root, rootPath, err := config.RootOf(project)
+	if err != nil {
+		return "", err
+	}
+	dir := rootPath
+	if contains(config.Templates, template) && template != root.Template {
+		dir = filepath.Join(rootPath, template)
+	}
+	path := filepath.Join(dir, filepath.Base(project.Path))
+	if path == project.Path {
+		return path, nil
+	}
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("%s already exists", ShortPath(path))
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	if err := os.Rename(project.Path, path); err != nil {
+		return "", err
+	}
+	moveHistory(project.Path, path)
+	return path, nil
+}
+
+// CopyTree copies a folder with its files, permissions and links
+func CopyTree(src string, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		}
+		//sockets, devices and pipes are left behind
+		return nil
+	})
+}
+
+func copyFile(src string, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,41 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenameProjectKeepingName(t *testing.T) {
+	//renames move the history of the project
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	config := Config{Folder_pattern: DefaultFolderPattern, Code_length: 3}
+	folder, err := FormatFolderName(config.Folder_pattern, "ABC", "my project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	project := Project{Path: filepath.Join(dir, folder)}
+	if err := os.Mkdir(project.Path, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := RenameProject(config, project, "my project", "ABC")
+	if err != nil {
+		t.Fatalf("renaming to the same name: %s", err)
+	}
+	if path != project.Path {
+		t.Errorf("renaming to the same name moved the project to %s", path)
+	}
+	if _, err := DuplicateProject(config, project, "my project", "ABC"); err == nil {
+		t.Error("duplicating onto the project itself did not fail")
+	}
+
+	path, err = RenameProject(config, project, "other project", "XYZ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("renamed project is missing: %s", err)
+	}
+}
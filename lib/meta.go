@@ -0,0 +1,156 @@
+package lib
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// file inside each project where nau keeps what it knows about it
+const MetadataFile = ".nau.json"
+
+type Metadata struct {
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Pinned      bool     `json:"pinned,omitempty"`
+	//command that opens the project instead of the template or global one
+	Open      string            `json:"open,omitempty"`
+	Launchers map[string]string `json:"launchers,omitempty"`
+}
+
+// ReadMetadata returns the metadata of a project, empty if it has none
+func ReadMetadata(projectPath string) (Metadata, error) {
+	var meta Metadata
+	data, err := os.ReadFile(filepath.Join(projectPath, MetadataFile))
+	if os.IsNotExist(err) {
+		return meta, nil
+	}
+	if err != nil {
+		return meta, err
+	}
+	err = json.Unmarshal(data, &meta)
+	return meta, err
+}
+
+func WriteMetadata(projectPath string, meta Metadata) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(projectPath, MetadataFile), append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	//keep the metadata out of the status and commits of repositories nau did not create
+	if dirExists(filepath.Join(projectPath, ".git")) {
+		addLine(filepath.Join(projectPath, ".git", "info", "exclude"), MetadataFile)
+	}
+	return nil
+}
+
+// IgnoreMetadata adds the metadata file to the .gitignore of a project, creating it if needed
+func IgnoreMetadata(projectPath string) error {
+	return addLine(filepath.Join(projectPath, ".gitignore"), MetadataFile)
+}
+
+// addLine appends line to a file unless it already has it
+func addLine(path string, line string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	text := string(data)
+	for _, existing := range strings.Split(text, "\n") {
+		if strings.TrimSpace(existing) == line {
+			return nil
+		}
+	}
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(text+line+"\n"), 0644)
+}
+
+// AddTags adds tags to a project, skipping the ones it already has
+func AddTags(projectPath string, tags []string) error {
+	meta, err := ReadMetadata(projectPath)
+	if err != nil {
+		return err
+	}
+	for _, tag := range normalizeTags(tags) {
+		if !containsString(meta.Tags, tag) {
+			meta.Tags = append(meta.Tags, tag)
+		}
+	}
+	return WriteMetadata(projectPath, meta)
+}
+
+// RemoveTags removes tags from a project, all of them when none are given
+func RemoveTags(projectPath string, tags []string) error {
+	meta, err := ReadMetadata(projectPath)
+	if err != nil {
+		return err
+	}
+	var kept []string
+	for _, tag := range meta.Tags {
+		if len(tags) > 0 && !containsString(normalizeTags(tags), tag) {
+			kept = append(kept, tag)
+		}
+	}
+	meta.Tags = kept
+	return WriteMetadata(projectPath, meta)
+}
+
+// SetPinned pins a project to the top of the grid or unpins it
+func SetPinned(projectPath string, pinned bool) error {
+	meta, err := ReadMetadata(projectPath)
+	if err != nil {
+		return err
+	}
+	meta.Pinned = pinned
+	return WriteMetadata(projectPath, meta)
+}
+
+func normalizeTags(tags []string) []string {
+	var normalized []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
+
+// refreshMetadata reads the tags and pins of the projects whose metadata changed since modTimes was taken,
+// they change without touching the folders the index watches. It updates modTimes and tells whether anything changed.
+func refreshMetadata(projects []Project, modTimes map[string]time.Time) bool {
+	current := make([]time.Time, len(projects))
+	changed := make([]bool, len(projects))
+	forEachProject(projects, func(i int) {
+		if info, err := os.Stat(filepath.Join(projects[i].Path, MetadataFile)); err == nil {
+			current[i] = info.ModTime()
+		}
+		previous, known := modTimes[projects[i].Path]
+		if known && previous.Equal(current[i]) {
+			return
+		}
+		changed[i] = true
+		meta, err := ReadMetadata(projects[i].Path)
+		if err != nil {
+			return
+		}
+		projects[i].Tags = meta.Tags
+		projects[i].Pinned = meta.Pinned
+	})
+	refreshed := false
+	for i, project := range projects {
+		modTimes[project.Path] = current[i]
+		refreshed = refreshed || changed[i]
+	}
+	return refreshed
+}
@@ -0,0 +1,220 @@
+package lib
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+var DefaultFolderPattern = "{{.Code}}_{{.Pascal}}"
+
+// data available inside FOLDER_PATTERN
+type FolderData struct {
+	Code   string
+	Pascal string
+	Hyphen string
+	Dunder string
+	Year   string
+}
+
+// regex used for each placeholder when reading folder names back
+var patternFields = map[string]string{
+	"Code":   `[A-Z0-9]+`,
+	"Pascal": `[A-Za-z0-9]+`,
+	"Hyphen": `[A-Za-z0-9]+(?:-[A-Za-z0-9]+)*`,
+	"Dunder": `[A-Za-z0-9]+(?:_[A-Za-z0-9]+)*`,
+	"Year":   `[0-9]{4}`,
+}
+
+var placeholderRegex = regexp.MustCompile(`{{\s*\.(\w+)\s*}}`)
+
+// the code placeholder together with the separator that goes along with it
+var codeAfterRegex = regexp.MustCompile(`{{\s*\.Code\s*}}[-_. ]?`)
+var codeBeforeRegex = regexp.MustCompile(`[-_. ]?{{\s*\.Code\s*}}`)
+
+func newFolderData(code string, name string) FolderData {
+	dunder := ToDunderName(name)
+	return FolderData{
+		Code:   strings.ToUpper(code),
+		Pascal: ToFolderName(dunder),
+		Hyphen: ToHyphenName(dunder),
+		Dunder: dunder,
+		Year:   strconv.Itoa(time.Now().Year()),
+	}
+}
+
+// FormatFolderName builds the folder name of a project from the configured pattern
+func FormatFolderName(pattern string, code string, name string) (string, error) {
+	if code == "" {
+		pattern = stripCode(pattern)
+	}
+	tmpl, err := template.New("folder").Option("missingkey=error").Parse(pattern)
+	if err != nil {
+		return "", err
+	}
+	var output strings.Builder
+	if err := tmpl.Execute(&output, newFolderData(code, name)); err != nil {
+		return "", err
+	}
+	return output.String(), nil
+}
+
+// ParseFolderName reads code and name back from a folder created with pattern
+func ParseFolderName(pattern string, folder string) (string, string, bool) {
+	re, err := folderRegex(pattern)
+	if err != nil {
+		return "", "", false
+	}
+	match := re.FindStringSubmatch(folder)
+	if match == nil {
+		//projects can be created without a code
+		re, err = folderRegex(stripCode(pattern))
+		if err != nil {
+			return "", "", false
+		}
+		match = re.FindStringSubmatch(folder)
+	}
+	if match == nil {
+		return "", "", false
+	}
+	var code, name string
+	for i, group := range re.SubexpNames() {
+		switch group {
+		case "Code":
+			code = match[i]
+		case "Pascal", "Hyphen", "Dunder":
+			if name == "" {
+				name = match[i]
+			}
+		}
+	}
+	if name == "" {
+		return "", "", false
+	}
+	return strings.ToUpper(code), name, true
+}
+
+// removes the code placeholder and its separator from a pattern
+func stripCode(pattern string) string {
+	loc := codeAfterRegex.FindStringIndex(pattern)
+	if loc == nil {
+		return pattern
+	}
+	//keep the separator if the code is the last thing in the pattern
+	if loc[0] > 0 && loc[1] == len(pattern) {
+		return codeBeforeRegex.ReplaceAllString(pattern, "")
+	}
+	return codeAfterRegex.ReplaceAllString(pattern, "")
+}
+
+func folderRegex(pattern string) (*regexp.Regexp, error) {
+	var expr strings.Builder
+	seen := make(map[string]bool)
+	last := 0
+	expr.WriteString("^")
+	for _, loc := range placeholderRegex.FindAllStringSubmatchIndex(pattern, -1) {
+		expr.WriteString(regexp.QuoteMeta(pattern[last:loc[0]]))
+		field := pattern[loc[2]:loc[3]]
+		fieldRegex, ok := patternFields[field]
+		if !ok {
+			return nil, fmt.Errorf("Unknown placeholder in folder pattern: %s", field)
+		}
+		//go regex does not allow repeated group names
+		if seen[field] {
+			expr.WriteString("(?:" + fieldRegex + ")")
+		} else {
+			expr.WriteString("(?P<" + field + ">" + fieldRegex + ")")
+			seen[field] = true
+		}
+		last = loc[1]
+	}
+	expr.WriteString(regexp.QuoteMeta(pattern[last:]))
+	expr.WriteString("$")
+	return regexp.Compile(expr.String())
+}
+
+func ValidateFolderPattern(pattern string) string {
+	if _, err := template.New("folder").Parse(pattern); err != nil {
+		return "• Not a valid template"
+	}
+	if _, err := folderRegex(pattern); err != nil {
+		return "• Unknown placeholder"
+	}
+	//only plain placeholders can be read back from folder names
+	rest := placeholderRegex.ReplaceAllString(pattern, "")
+	if strings.Contains(rest, "{{") || strings.Contains(rest, "}}") {
+		return "• Only placeholders such as {{.Code}} and {{.Pascal}} are allowed"
+	}
+	if !strings.Contains(pattern, ".Pascal") && !strings.Contains(pattern, ".Hyphen") && !strings.Contains(pattern, ".Dunder") {
+		return "• Pattern needs a name placeholder"
+	}
+	if strings.Contains(pattern, "/") {
+		return "• Pattern cannot contain /"
+	}
+	return ""
+}
+
+// SuggestCode builds a code from the initials of name that is not in existing
+func SuggestCode(name string, length int, existing []string) string {
+	if length <= 0 {
+		return ""
+	}
+	var initials, rest []rune
+	for _, word := range strings.Fields(ToDisplayName(ToDunderName(name))) {
+		for i, c := range strings.ToUpper(word) {
+			if !isCodeRune(c) {
+				continue
+			}
+			if i == 0 {
+				initials = append(initials, c)
+			} else {
+				rest = append(rest, c)
+			}
+		}
+	}
+	letters := append(initials, rest...)
+	for len(letters) < length {
+		letters = append(letters, 'X')
+	}
+	code := string(letters[:length])
+	if !containsString(existing, code) {
+		return code
+	}
+	//vary the last character until there is no collision
+	pool := append(letters[length:], []rune("ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789")...)
+	base := code[:length-1]
+	for _, c := range pool {
+		candidate := base + string(c)
+		if !containsString(existing, candidate) {
+			return candidate
+		}
+	}
+	return code
+}
+
+func isCodeRune(c rune) bool {
+	return (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func containsString(slice []string, str string) bool {
+	for _, s := range slice {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
+
+func ValidateCodeLength(value string) string {
+	length, err := strconv.Atoi(value)
+	if err != nil {
+		return "• Not a number"
+	}
+	if length != 0 && (length < 2 || length > 5) {
+		return "• Use 0 or a length from 2 to 5"
+	}
+	return ""
+}
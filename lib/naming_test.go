@@ -0,0 +1,59 @@
+package lib
+
+import "testing"
+
+func TestFolderNameRoundTrip(t *testing.T) {
+	patterns := []string{
+		DefaultFolderPattern,
+		"{{.Pascal}}_{{.Code}}",
+		"{{.Code}}-{{.Hyphen}}",
+		"{{.Year}}_{{.Dunder}}_{{.Code}}",
+		"{{ .Hyphen }}.{{ .Code }}",
+	}
+	names := []string{"my project", "Deep Learning v2", "single"}
+	for _, pattern := range patterns {
+		for _, length := range []int{0, 2, 3, 5} {
+			for _, name := range names {
+				code := SuggestCode(name, length, nil)
+				folder, err := FormatFolderName(pattern, code, name)
+				if err != nil {
+					t.Fatalf("FormatFolderName(%q, %q, %q): %s", pattern, code, name, err)
+				}
+				gotCode, gotName, ok := ParseFolderName(pattern, folder)
+				if !ok {
+					t.Errorf("ParseFolderName(%q, %q) did not match", pattern, folder)
+					continue
+				}
+				if gotCode != code {
+					t.Errorf("ParseFolderName(%q, %q) code = %q, want %q", pattern, folder, gotCode, code)
+				}
+				if ToDunderName(gotName) != ToDunderName(name) {
+					t.Errorf("ParseFolderName(%q, %q) name = %q, want %q", pattern, folder, gotName, name)
+				}
+			}
+		}
+	}
+}
+
+func TestValidateFolderPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		valid   bool
+	}{
+		{DefaultFolderPattern, true},
+		{"{{ .Code }}-{{ .Hyphen }}", true},
+		{"{{.Code}}", false},
+		{"{{.Code}}/{{.Pascal}}", false},
+		{"{{.Unknown}}_{{.Pascal}}", false},
+		{"{{if .Code}}{{.Code}}_{{end}}{{.Pascal}}", false},
+		{"{{range .Code}}{{end}}{{.Pascal}}", false},
+		{"{{.Code | printf \"%s\"}}_{{.Pascal}}", false},
+		{"{{- .Code}}_{{.Pascal}}", false},
+	}
+	for _, test := range tests {
+		message := ValidateFolderPattern(test.pattern)
+		if (message == "") != test.valid {
+			t.Errorf("ValidateFolderPattern(%q) = %q, want valid %v", test.pattern, message, test.valid)
+		}
+	}
+}
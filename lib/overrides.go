@@ -0,0 +1,95 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// where the value of a config field comes from, later ones win
+const (
+	OriginDefault = "default"
+	OriginFile    = "file"
+	OriginEnv     = "env"
+	OriginFlag    = "flag"
+)
+
+// prefix of the environment variables that override config fields, as in NAU_EDITOR
+const envPrefix = "NAU_"
+
+// FIELD=value pairs given with --set
+var configSets []string
+
+// SetOverrides makes nau use the FIELD=value pairs given with --set over everything else
+func SetOverrides(sets []string) {
+	configSets = sets
+}
+
+// applyOverrides sets the fields given in NAU_<FIELD> variables and then with --set
+func applyOverrides(config Config) (Config, error) {
+	for _, field := range CustomizableFields {
+		value, ok := os.LookupEnv(envPrefix + field)
+		if !ok {
+			continue
+		}
+		if err := config.setField(field, value); err != nil {
+			return Config{}, fmt.Errorf("%s%s: %s", envPrefix, field, err)
+		}
+		config.Origins[field] = OriginEnv
+	}
+	for _, set := range configSets {
+		parts := strings.SplitN(set, "=", 2)
+		field := strings.ToUpper(strings.TrimSpace(parts[0]))
+		if len(parts) != 2 || !containsString(CustomizableFields, field) {
+			return Config{}, fmt.Errorf("--set %s is not FIELD=value with one of %s", set, strings.Join(CustomizableFields, ", "))
+		}
+		if err := config.setField(field, parts[1]); err != nil {
+			return Config{}, fmt.Errorf("--set %s: %s", field, err)
+		}
+		config.Origins[field] = OriginFlag
+	}
+	return config, nil
+}
+
+// setField sets a field from text, checking what can be checked without touching the disk
+func (c *Config) setField(field string, value string) error {
+	switch field {
+	case "BASE_COLOR", "FOLDER_PATTERN", "CODE_LENGTH", "STAY_OPEN", "MULTIPLEXER":
+		if message := ValidateValue(field, value); message != "" {
+			return errors.New(strings.TrimPrefix(message, "• "))
+		}
+	}
+	switch field {
+	case "AUTHOR":
+		c.Author = value
+	case "EMAIL":
+		c.Email = value
+	case "REMOTE":
+		c.Remote = value
+	case "BASE_COLOR":
+		c.Base_color = value
+	case "EDITOR":
+		c.Editor = value
+	case "PROJECTS_PATH":
+		roots, err := ParseRoots(value)
+		if err != nil {
+			return err
+		}
+		c.Projects_path, c.Roots = value, roots
+	case "TEMPLATES_PATH":
+		c.Templates_path = value
+	case "ARCHIVES_PATH":
+		c.Archives_path = value
+	case "FOLDER_PATTERN":
+		c.Folder_pattern = value
+	case "CODE_LENGTH":
+		c.Code_length, _ = strconv.Atoi(value)
+	case "STAY_OPEN":
+		c.Stay_open, _ = strconv.ParseBool(value)
+	case "MULTIPLEXER":
+		c.Multiplexer = value
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/antonio-leitao/nau/internal/testconfig"
+)
+
+func TestOverridePrecedence(t *testing.T) {
+	testconfig.Use(t, "base_color = \"#111111\"\ncode_length = 4\nfolder_pattern = \"{{.Pascal}}_{{.Code}}\"\n")
+	t.Setenv("NAU_CODE_LENGTH", "2")
+	t.Setenv("NAU_FOLDER_PATTERN", "{{.Code}}-{{.Hyphen}}")
+	SetOverrides([]string{"folder_pattern={{.Hyphen}}"})
+	t.Cleanup(func() { SetOverrides(nil) })
+	config, err := ReadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		field  string
+		value  string
+		origin string
+	}{
+		{"STAY_OPEN", "false", OriginDefault},
+		{"BASE_COLOR", "#111111", OriginFile},
+		{"CODE_LENGTH", "2", OriginEnv},
+		{"FOLDER_PATTERN", "{{.Hyphen}}", OriginFlag},
+	}
+	for _, test := range tests {
+		value, _ := FieldValue(config, test.field)
+		if value != test.value || config.Origins[test.field] != test.origin {
+			t.Errorf("%s = %q from %s, want %q from %s", test.field, value, config.Origins[test.field], test.value, test.origin)
+		}
+	}
+	saved, err := ReadSavedConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Code_length != 4 || saved.Folder_pattern != "{{.Pascal}}_{{.Code}}" {
+		t.Errorf("ReadSavedConfig applied the overrides: %d %q", saved.Code_length, saved.Folder_pattern)
+	}
+}
+
+func TestOverrideErrors(t *testing.T) {
+	tests := []struct {
+		env  string
+		sets []string
+	}{
+		{env: "blue"},
+		{sets: []string{"BASE_COLOR"}},
+		{sets: []string{"UNKNOWN=1"}},
+		{sets: []string{"CODE_LENGTH=9"}},
+	}
+	for _, test := range tests {
+		testconfig.Use(t, "")
+		if test.env != "" {
+			t.Setenv("NAU_BASE_COLOR", test.env)
+		}
+		SetOverrides(test.sets)
+		t.Cleanup(func() { SetOverrides(nil) })
+		if _, err := ReadConfig(); err == nil {
+			t.Errorf("ReadConfig with NAU_BASE_COLOR=%q and --set %q did not fail", test.env, test.sets)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package lib
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// keys that can be used as key:value in a filter
+var QualifierKeys = []string{"lang", "code", "tag", "root", "group", "dirty", "modified", "archived"}
+
+type Qualifier struct {
+	Key   string
+	Value string
+	//parsed values
+	yes    bool
+	newer  bool //modified:<age
+	maxAge time.Duration
+}
+
+// a filter typed in the root grid, free text and qualifiers
+type Query struct {
+	Text       string
+	Qualifiers []Qualifier
+	Errors     []string
+}
+
+// ParseQuery splits a filter into its qualifiers and free text. Unknown keys are free text.
+func ParseQuery(s string) Query {
+	var query Query
+	var text []string
+	for _, word := range strings.Fields(s) {
+		parts := strings.SplitN(word, ":", 2)
+		if len(parts) != 2 || !containsString(QualifierKeys, strings.ToLower(parts[0])) {
+			text = append(text, word)
+			continue
+		}
+		q := Qualifier{Key: strings.ToLower(parts[0]), Value: parts[1]}
+		if q.Value == "" {
+			//still being typed
+			continue
+		}
+		if err := q.parse(); err != nil {
+			query.Errors = append(query.Errors, err.Error())
+			continue
+		}
+		query.Qualifiers = append(query.Qualifiers, q)
+	}
+	query.Text = strings.Join(text, " ")
+	return query
+}
+
+func (q *Qualifier) parse() error {
+	var err error
+	switch q.Key {
+	case "dirty", "archived":
+		q.yes, err = parseYes(q.Value)
+	case "modified":
+		q.newer, q.maxAge, err = parseAge(q.Value)
+	}
+	if err != nil {
+		return fmt.Errorf("%s:%s %s", q.Key, q.Value, err)
+	}
+	return nil
+}
+
+func parseYes(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "yes", "y", "true":
+		return true, nil
+	case "no", "n", "false":
+		return false, nil
+	}
+	return false, fmt.Errorf("is not yes or no")
+}
+
+// reads <30d, >2w or 6m, an age without < means newer than it
+func parseAge(value string) (bool, time.Duration, error) {
+	newer := !strings.HasPrefix(value, ">")
+	value = strings.TrimLeft(value, "<>")
+	if len(value) < 2 {
+		return false, 0, fmt.Errorf("is not an age like <30d")
+	}
+	n, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil {
+		return false, 0, fmt.Errorf("is not an age like <30d")
+	}
+	day := 24 * time.Hour
+	units := map[byte]time.Duration{'h': time.Hour, 'd': day, 'w': 7 * day, 'm': 30 * day, 'y': 365 * day}
+	unit, ok := units[value[len(value)-1]]
+	if !ok {
+		return false, 0, fmt.Errorf("needs a unit of h, d, w, m or y")
+	}
+	return newer, time.Duration(n) * unit, nil
+}
+
+// Archived tells whether the query is about archived projects
+func (q Query) Archived() bool {
+	for _, qualifier := range q.Qualifiers {
+		if qualifier.Key == "archived" {
+			return qualifier.yes
+		}
+	}
+	return false
+}
+
+// Has tells whether the query uses a qualifier
+func (q Query) Has(key string) bool {
+	for _, qualifier := range q.Qualifiers {
+		if qualifier.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches checks a project against all the qualifiers
+func (q Query) Matches(project Project, status GitStatus) bool {
+	for _, qualifier := range q.Qualifiers {
+		if !qualifier.Matches(project, status) {
+			return false
+		}
+	}
+	return true
+}
+
+func (q Qualifier) Matches(project Project, status GitStatus) bool {
+	value := strings.ToLower(q.Value)
+	switch q.Key {
+	case "lang":
+		return strings.HasPrefix(strings.ToLower(project.Lang), value)
+	case "code":
+		return strings.HasPrefix(strings.ToLower(project.Code), value)
+	case "tag":
+		return containsString(project.Tags, value)
+	case "root":
+		return strings.HasPrefix(strings.ToLower(project.Root), value)
+	case "group":
+		return strings.Contains(strings.ToLower(project.Group), value)
+	case "dirty":
+		return status.Dirty() == q.yes
+	case "modified":
+		age := time.Since(project.Timestamp)
+		if q.newer {
+			return age < q.maxAge
+		}
+		return age > q.maxAge
+	case "archived":
+		return project.Archived == q.yes
+	}
+	return true
+}
+
+func (q Qualifier) String() string {
+	return q.Key + ":" + q.Value
+}
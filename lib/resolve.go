@@ -0,0 +1,55 @@
+package lib
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/sahilm/fuzzy"
+)
+
+// matches closer than this to the best one are too close to choose between
+const ambiguityGap = 4
+
+// most projects offered when a query is ambiguous
+const maxCandidates = 9
+
+// projects matched by name
+type nameSource []Project
+
+func (s nameSource) String(i int) string {
+	return s[i].Name
+}
+func (s nameSource) Len() int {
+	return len(s)
+}
+
+// isExact tells whether query is the code or the folder name of a project
+func isExact(project Project, query string) bool {
+	query = strings.ToLower(strings.TrimSpace(query))
+	return strings.ToLower(project.Code) == query ||
+		strings.ToLower(filepath.Base(project.Path)) == query ||
+		project.Repo_name == ToHyphenName(ToDunderName(query))
+}
+
+// Resolve finds the projects a query can refer to, best first. Exact codes and folder names
+// win outright, otherwise every match close to the best one is returned.
+func Resolve(projects []Project, query string, history History) []Project {
+	var exact []Project
+	for _, project := range projects {
+		if isExact(project, query) {
+			exact = append(exact, project)
+		}
+	}
+	if len(exact) > 0 {
+		return exact
+	}
+	ranked := history.Rank(projects, fuzzy.FindFrom(query, nameSource(projects)))
+	var candidates []Project
+	for _, r := range ranked {
+		if r.Score < ranked[0].Score-ambiguityGap || len(candidates) == maxCandidates {
+			break
+		}
+		candidates = append(candidates, r.Project)
+	}
+	return candidates
+}
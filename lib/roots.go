@@ -0,0 +1,215 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// file that turns any folder inside a root into a group of projects
+const GroupMarker = ".naugroup"
+
+// a folder where projects are stored
+type Root struct {
+	Path     string
+	Label    string
+	Template string //template of the projects that are not inside a template folder
+}
+
+// ParseRoots reads PROJECTS_PATH, a comma separated list of path[:label[:template]]
+func ParseRoots(value string) ([]Root, error) {
+	var roots []Root
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		root := Root{Path: strings.TrimSpace(parts[0])}
+		if root.Path == "" {
+			return nil, fmt.Errorf("Invalid projects path: %s", entry)
+		}
+		if len(parts) > 1 {
+			root.Label = strings.TrimSpace(parts[1])
+		}
+		if len(parts) > 2 {
+			root.Template = strings.TrimSpace(parts[2])
+		}
+		if root.Label == "" {
+			root.Label = filepath.Base(root.Path)
+		}
+		roots = append(roots, root)
+	}
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("No projects path configured")
+	}
+	return roots, nil
+}
+
+// FormatRoots writes roots back in the form of PROJECTS_PATH
+func FormatRoots(roots []Root) string {
+	var entries []string
+	for _, root := range roots {
+		entry := root.Path + ":" + root.Label
+		if root.Template != "" {
+			entry += ":" + root.Template
+		}
+		entries = append(entries, entry)
+	}
+	return strings.Join(entries, ",")
+}
+
+// RootFor returns the root where new projects of a template are created
+func (c Config) RootFor(template string) Root {
+	for _, root := range c.Roots {
+		if root.Template == template {
+			return root
+		}
+	}
+	return c.Roots[0]
+}
+
+// RootOf returns the root a project lives in and its expanded path, the deepest one when roots are nested
+func (c Config) RootOf(project Project) (Root, string, error) {
+	var found Root
+	var foundPath string
+	for _, root := range c.Roots {
+		rootPath, err := ExpandPath(root.Path)
+		if err != nil {
+			return Root{}, "", err
+		}
+		rootPath = filepath.Clean(rootPath)
+		if !strings.HasPrefix(project.Path, rootPath+string(filepath.Separator)) || len(rootPath) <= len(foundPath) {
+			continue
+		}
+		found, foundPath = root, rootPath
+	}
+	if foundPath == "" {
+		return Root{}, "", fmt.Errorf("%s is not inside any of the project roots", ShortPath(project.Path))
+	}
+	return found, foundPath, nil
+}
+
+// NewProjectPath returns the directory where a new project of template is placed
+func (c Config) NewProjectPath(template string, folder_name string) (string, error) {
+	root := c.RootFor(template)
+	rootPath, err := ExpandPath(root.Path)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := c.Templates[template]; !ok || root.Template == template {
+		return filepath.Join(rootPath, folder_name), nil
+	}
+	return filepath.Join(rootPath, template, folder_name), nil
+}
+
+func validateRoots(value string) string {
+	roots, err := ParseRoots(value)
+	if err != nil {
+		return "• " + err.Error()
+	}
+	for _, root := range roots {
+		path, err := ExpandPath(root.Path)
+		if err != nil {
+			return "• " + err.Error()
+		}
+		if !dirExists(path) {
+			return "• Directory does not exist: " + root.Path
+		}
+	}
+	return ""
+}
+
+func isGroup(dirPath string, name string, templates map[string]string) bool {
+	if contains(templates, name) {
+		return true
+	}
+	_, err := os.Stat(filepath.Join(dirPath, GroupMarker))
+	return err == nil
+}
+
+// scanGroup lists the projects inside a root or group folder, recursing into nested groups.
+// Folders in roots are other roots and are left to their own scan.
+// The modification time of every folder read is stored in dirs.
+func scanGroup(config Config, root Root, dirPath string, group string, lang string, color string, roots map[string]bool, dirs map[string]time.Time) ([]Project, error) {
+	var projects []Project
+	info, err := os.Stat(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	dirs[dirPath] = info.ModTime()
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !validEntry(entry) {
+			continue
+		}
+		path := filepath.Join(dirPath, entry.Name())
+		if roots[path] {
+			continue
+		}
+		if isGroup(path, entry.Name(), config.Templates) {
+			subLang, subColor := lang, color
+			if contains(config.Templates, entry.Name()) {
+				subLang, subColor = entry.Name(), config.Templates[entry.Name()]
+			}
+			groupProjects, err := scanGroup(config, root, path, filepath.Join(group, entry.Name()), subLang, subColor, roots, dirs)
+			if err != nil {
+				return nil, err
+			}
+			projects = append(projects, groupProjects...)
+			continue
+		}
+		code, name, folder_name, repo_name, display_name := discombobulate(config.Folder_pattern, entry.Name())
+		projects = append(projects, Project{
+			Name:         name,
+			Folder_name:  folder_name,
+			Repo_name:    repo_name,
+			Display_Name: display_name,
+			Code:         code,
+			Lang:         lang,
+			Color:        color,
+			Path:         path,
+			Root:         root.Label,
+			Group:        group,
+		})
+	}
+	return projects, nil
+}
+
+// scanRoots lists the projects of every root. A root that is missing or cannot be read, such as a
+// disk that is not mounted, is skipped with a warning so nau keeps working and can be reconfigured.
+func scanRoots(config Config, dirs map[string]time.Time) ([]Project, error) {
+	rootPaths := make([]string, len(config.Roots))
+	roots := make(map[string]bool)
+	for i, root := range config.Roots {
+		rootPath, err := ExpandPath(root.Path)
+		if err != nil {
+			return nil, err
+		}
+		rootPaths[i] = filepath.Clean(rootPath)
+		roots[rootPaths[i]] = true
+	}
+	var projects []Project
+	for i, root := range config.Roots {
+		lang, color := "Mixed", config.Base_color
+		if contains(config.Templates, root.Template) {
+			lang, color = root.Template, config.Templates[root.Template]
+		}
+		rootProjects, err := scanGroup(config, root, rootPaths[i], "", lang, color, roots, dirs)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "NAU warning: skipping the projects in %s: %s\n", root.Path, err)
+			//the index is rebuilt once the root is back
+			if _, err := os.Stat(rootPaths[i]); os.IsNotExist(err) {
+				dirs[rootPaths[i]] = time.Time{}
+			}
+			continue
+		}
+		projects = append(projects, rootProjects...)
+	}
+	return projects, nil
+}
@@ -0,0 +1,58 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestScanRoots(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	projects := t.TempDir()
+	//outside of the other roots so it showing up does not touch them
+	missing := filepath.Join(t.TempDir(), "missing")
+	for _, dir := range []string{"ABC_One", "work/DEF_Two", "work/Python/GHI_Three"} {
+		if err := os.MkdirAll(filepath.Join(projects, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config := Config{
+		Folder_pattern: DefaultFolderPattern,
+		Templates:      map[string]string{"Python": "#3776AB"},
+		Roots: []Root{
+			{Path: projects, Label: "all"},
+			{Path: filepath.Join(projects, "work"), Label: "work"},
+			{Path: missing, Label: "missing"},
+		},
+	}
+	found, err := Reindex(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, project := range found {
+		got = append(got, project.Code+" "+project.Root+" "+project.Lang)
+	}
+	sort.Strings(got)
+	want := []string{"ABC all Mixed", "DEF work Mixed", "GHI work Python"}
+	if len(got) != len(want) {
+		t.Fatalf("projects = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("projects = %q, want %q", got, want)
+			break
+		}
+	}
+	//the missing root keeps the index valid until it shows up
+	if _, ok := loadIndex(config); !ok {
+		t.Error("the index is not valid while a root is missing")
+	}
+	if err := os.MkdirAll(missing, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := loadIndex(config); ok {
+		t.Error("the index is still valid once the missing root is back")
+	}
+}
@@ -0,0 +1,154 @@
+package lib
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// multiplexers nau can keep project sessions in
+var Multiplexers = []string{"tmux", "zellij"}
+
+// config fields with session layouts, SESSION_<TEMPLATE> takes the template after the prefix
+const (
+	sessionField          = "SESSION"
+	templateSessionPrefix = "SESSION_"
+)
+
+// window command that runs the launcher of the project
+const OpenWindow = "@open"
+
+// layout used when none is set, the launcher next to a shell
+const defaultLayout = "editor:" + OpenWindow + ";shell:"
+
+// a window of a project session
+type Window struct {
+	Name    string
+	Command string //empty for a shell
+}
+
+func isSessionField(field string) bool {
+	return field == sessionField ||
+		(strings.HasPrefix(field, templateSessionPrefix) && len(field) > len(templateSessionPrefix))
+}
+
+// SessionField returns the layout set for a session field such as SESSION_PYTHON
+func (c Config) SessionField(field string) (string, bool) {
+	field = strings.ToUpper(field)
+	switch {
+	case !isSessionField(field):
+		return "", false
+	case field == sessionField:
+		return c.Session, true
+	default:
+		return c.Per_template[strings.ToLower(strings.TrimPrefix(field, templateSessionPrefix))].Session, true
+	}
+}
+
+// ParseLayout reads windows written as name:command separated by semicolons
+func ParseLayout(layout string) ([]Window, error) {
+	var windows []Window
+	for _, part := range strings.Split(layout, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		pieces := strings.SplitN(part, ":", 2)
+		if len(pieces) != 2 || strings.TrimSpace(pieces[0]) == "" {
+			return nil, fmt.Errorf("Window %q is not name:command", part)
+		}
+		windows = append(windows, Window{Name: strings.TrimSpace(pieces[0]), Command: strings.TrimSpace(pieces[1])})
+	}
+	if len(windows) == 0 {
+		return nil, fmt.Errorf("Layout has no windows")
+	}
+	return windows, nil
+}
+
+func validateLayout(layout string) string {
+	windows, err := ParseLayout(layout)
+	if err != nil {
+		return "• " + err.Error()
+	}
+	for _, window := range windows {
+		if window.Command == "" || window.Command == OpenWindow {
+			continue
+		}
+		if message := validateLauncher(window.Command); message != "" {
+			return message
+		}
+	}
+	return ""
+}
+
+// SessionLayout returns the windows of a project session, from its template's layout, the global one or the default
+func (c Config) SessionLayout(project Project) ([]Window, error) {
+	for _, layout := range []string{c.Per_template[strings.ToLower(project.Lang)].Session, c.Session} {
+		if layout != "" {
+			return ParseLayout(layout)
+		}
+	}
+	return ParseLayout(defaultLayout)
+}
+
+// SessionName is the name of the session of a project, its code or else its hyphen name followed by a
+// short hash of its path, so projects with the same code or name in different roots get their own session
+func SessionName(project Project) string {
+	name := project.Code
+	if name == "" {
+		name = ToHyphenName(project.Name)
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(project.Path))
+	name = fmt.Sprintf("%s-%06x", name, hash.Sum32()&0xffffff)
+	//tmux does not allow these in names
+	return strings.NewReplacer(".", "-", ":", "-").Replace(name)
+}
+
+// MultiplexerPath finds the multiplexer on PATH
+func MultiplexerPath(multiplexer string) (string, error) {
+	path, err := exec.LookPath(multiplexer)
+	if err != nil {
+		return "", fmt.Errorf("%s was not found on PATH, install it or choose another one with nau config multiplexer", multiplexer)
+	}
+	return path, nil
+}
+
+// LiveSessions returns the names of the sessions running, none when the multiplexer is not there
+func LiveSessions(multiplexer string) map[string]bool {
+	live := make(map[string]bool)
+	path, err := MultiplexerPath(multiplexer)
+	if err != nil {
+		return live
+	}
+	var cmd *exec.Cmd
+	switch multiplexer {
+	case "zellij":
+		cmd = exec.Command(path, "list-sessions", "--short")
+	default:
+		cmd = exec.Command(path, "list-sessions", "-F", "#{session_name}")
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		//no server running
+		return live
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			live[fields[0]] = true
+		}
+	}
+	return live
+}
+
+// InsideMultiplexer tells whether nau runs inside a session of the multiplexer
+func InsideMultiplexer(multiplexer string) bool {
+	switch multiplexer {
+	case "zellij":
+		return os.Getenv("ZELLIJ") != ""
+	default:
+		return os.Getenv("TMUX") != ""
+	}
+}
@@ -0,0 +1,90 @@
+package lib
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLayout(t *testing.T) {
+	tests := []struct {
+		layout  string
+		windows []Window
+		wantErr bool
+	}{
+		{"editor:@open;shell:", []Window{{"editor", "@open"}, {"shell", ""}}, false},
+		{" repl : ipython ; ", []Window{{"repl", "ipython"}}, false},
+		{"server:npm run dev:watch", []Window{{"server", "npm run dev:watch"}}, false},
+		{"", nil, true},
+		{";;", nil, true},
+		{"editor", nil, true},
+		{":vim", nil, true},
+	}
+	for _, test := range tests {
+		windows, err := ParseLayout(test.layout)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ParseLayout(%q) error = %v, want error %v", test.layout, err, test.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(windows, test.windows) {
+			t.Errorf("ParseLayout(%q) = %v, want %v", test.layout, windows, test.windows)
+		}
+	}
+}
+
+func TestSessionLayout(t *testing.T) {
+	config := Config{
+		Session: "main:@open",
+		Per_template: map[string]TemplateOptions{
+			"python": {Session: "editor:@open;repl:ipython"},
+			"rust":   {Open: "code {{.Path}}"},
+		},
+	}
+	tests := []struct {
+		config  Config
+		lang    string
+		windows []Window
+	}{
+		{config, "Python", []Window{{"editor", "@open"}, {"repl", "ipython"}}},
+		{config, "Rust", []Window{{"main", "@open"}}},
+		{config, "Empty", []Window{{"main", "@open"}}},
+		{Config{}, "Python", []Window{{"editor", "@open"}, {"shell", ""}}},
+	}
+	for _, test := range tests {
+		windows, err := test.config.SessionLayout(Project{Lang: test.lang})
+		if err != nil {
+			t.Errorf("SessionLayout(%s): %s", test.lang, err)
+			continue
+		}
+		if !reflect.DeepEqual(windows, test.windows) {
+			t.Errorf("SessionLayout(%s) = %v, want %v", test.lang, windows, test.windows)
+		}
+	}
+}
+
+func TestSessionName(t *testing.T) {
+	tests := []struct {
+		project Project
+		prefix  string
+	}{
+		{Project{Code: "ABC", Name: "my_project", Path: "/work/ABC_my_project"}, "ABC-"},
+		{Project{Name: "my_project", Path: "/work/my_project"}, "my-project-"},
+		{Project{Name: "MyProject", Path: "/work/MyProject"}, "my-project-"},
+		{Project{Name: "v1.2:beta", Path: "/work/v1.2:beta"}, "v1-2-beta-"},
+	}
+	for _, test := range tests {
+		name := SessionName(test.project)
+		if !strings.HasPrefix(name, test.prefix) || len(name) != len(test.prefix)+6 {
+			t.Errorf("SessionName(%+v) = %q, want %q and a hash of the path", test.project, name, test.prefix)
+		}
+		if strings.ContainsAny(name, ".:") {
+			t.Errorf("SessionName(%+v) = %q, which tmux does not allow", test.project, name)
+		}
+	}
+	//the same code in another root is another session
+	a := SessionName(Project{Code: "ABC", Path: "/work/ABC_my_project"})
+	b := SessionName(Project{Code: "ABC", Path: "/home/ABC_my_project"})
+	if a == b {
+		t.Errorf("projects in different roots share the session %q", a)
+	}
+}
@@ -0,0 +1,84 @@
+package lib
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// choices made in the root TUI that are kept between runs
+type UIState struct {
+	View    string
+	Sort    string
+	Reverse bool
+	Grouped bool
+}
+
+// StateDir returns the folder where nau keeps state that is not configuration
+func StateDir() (string, error) {
+	return xdgDir("XDG_STATE_HOME", "~/.local/state")
+}
+
+func uiStatePath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ui.json"), nil
+}
+
+// LoadUIState returns the saved state, or the defaults when there is none
+func LoadUIState() UIState {
+	state := UIState{View: "grid", Sort: "modified"}
+	path, err := uiStatePath()
+	if err != nil {
+		return state
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return state
+	}
+	json.Unmarshal(data, &state)
+	return state
+}
+
+func SaveUIState(state UIState) error {
+	path, err := uiStatePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func onboardedPath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "onboarded"), nil
+}
+
+// OnboardingDismissed tells whether the first run form was already shown and left without saving
+func OnboardingDismissed() bool {
+	path, err := onboardedPath()
+	return err == nil && fileExists(path)
+}
+
+// DismissOnboarding keeps the first run form from showing up again
+func DismissOnboarding() error {
+	path, err := onboardedPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, nil, 0644)
+}
@@ -0,0 +1,123 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// limits of the walk used for projects that are not repositories
+const (
+	timestampMaxDepth   = 4
+	timestampMaxEntries = 5000
+)
+
+var errStopWalk = errors.New("stop walking")
+
+// folders that change without the project being worked on
+var ignoredDirs = map[string]bool{
+	"node_modules": true,
+	"target":       true,
+	"vendor":       true,
+	"venv":         true,
+	"__pycache__":  true,
+	"dist":         true,
+	"build":        true,
+}
+
+// getDirectoryTimestamp returns when a project was last worked on
+func getDirectoryTimestamp(dirPath string) (time.Time, error) {
+	// Check if the directory exists
+	fileInfo, err := os.Stat(dirPath)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !fileInfo.IsDir() {
+		return time.Time{}, fmt.Errorf("Could not read timestamp from %s", dirPath)
+	}
+	// repositories change HEAD and the index whenever someone commits, checks out or stages
+	if gitDir, ok := findGitDir(dirPath); ok {
+		if timestamp, ok := gitTimestamp(gitDir); ok {
+			return timestamp, nil
+		}
+	}
+	return newestModTime(dirPath), nil
+}
+
+// findGitDir resolves the git directory of a project, following .git files of worktrees and submodules
+func findGitDir(dirPath string) (string, bool) {
+	gitPath := filepath.Join(dirPath, ".git")
+	info, err := os.Stat(gitPath)
+	if err != nil {
+		return "", false
+	}
+	if info.IsDir() {
+		return gitPath, true
+	}
+	content, err := os.ReadFile(gitPath)
+	if err != nil {
+		return "", false
+	}
+	line := strings.TrimSpace(string(content))
+	if !strings.HasPrefix(line, "gitdir:") {
+		return "", false
+	}
+	gitDir := strings.TrimSpace(strings.TrimPrefix(line, "gitdir:"))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(dirPath, gitDir)
+	}
+	return gitDir, true
+}
+
+func gitTimestamp(gitDir string) (time.Time, bool) {
+	var latest time.Time
+	for _, name := range []string{"HEAD", "index", filepath.Join("logs", "HEAD")} {
+		info, err := os.Stat(filepath.Join(gitDir, name))
+		if err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, !latest.IsZero()
+}
+
+// newestModTime walks a few levels of the project and returns the newest modification time
+func newestModTime(dirPath string) time.Time {
+	var latest time.Time
+	walkProject(dirPath, timestampMaxDepth, timestampMaxEntries, func(d fs.DirEntry) {
+		info, err := d.Info()
+		if err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	})
+	return latest
+}
+
+// walkProject calls visit with the entries of a project down to maxDepth, leaving out hidden and
+// ignored folders. It stops after maxEntries and reports whether every entry was visited.
+func walkProject(dirPath string, maxDepth int, maxEntries int, visit func(d fs.DirEntry)) bool {
+	entries := 0
+	err := filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() && path != dirPath {
+			if strings.HasPrefix(d.Name(), ".") || ignoredDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			if strings.Count(path[len(dirPath):], string(filepath.Separator)) >= maxDepth {
+				return filepath.SkipDir
+			}
+		}
+		entries++
+		if entries > maxEntries {
+			return errStopWalk
+		}
+		visit(d)
+		return nil
+	})
+	return err == nil
+}
@@ -0,0 +1,76 @@
+package lib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// launchers in .nau.json come with the repository of a project, so they only run once the
+// user trusted them. Trust is kept by path together with a digest of the commands, changing
+// them takes it away.
+
+func trustPath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "trusted.json"), nil
+}
+
+// launchersDigest identifies the commands the metadata of a project sets
+func launchersDigest(meta Metadata) string {
+	//maps are encoded with sorted keys
+	data, _ := json.Marshal(struct {
+		Open      string
+		Launchers map[string]string
+	}{meta.Open, meta.Launchers})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func loadTrusted() map[string]string {
+	trusted := make(map[string]string)
+	path, err := trustPath()
+	if err != nil {
+		return trusted
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return trusted
+	}
+	json.Unmarshal(data, &trusted)
+	return trusted
+}
+
+func launchersTrusted(projectPath string, meta Metadata) bool {
+	return loadTrusted()[projectPath] == launchersDigest(meta)
+}
+
+// TrustLaunchers lets the launchers currently in the metadata of a project run
+func TrustLaunchers(projectPath string) error {
+	meta, err := ReadMetadata(projectPath)
+	if err != nil {
+		return err
+	}
+	trusted := loadTrusted()
+	trusted[projectPath] = launchersDigest(meta)
+	path, err := trustPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(trusted)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -7,14 +7,28 @@ import (
 	configure "github.com/antonio-leitao/nau/cmd/configure"
 	new "github.com/antonio-leitao/nau/cmd/new"
 	open "github.com/antonio-leitao/nau/cmd/open"
+	reindex "github.com/antonio-leitao/nau/cmd/reindex"
+	shell "github.com/antonio-leitao/nau/cmd/shell"
 	show "github.com/antonio-leitao/nau/cmd/show"
+	tag "github.com/antonio-leitao/nau/cmd/tag"
 	lib "github.com/antonio-leitao/nau/lib"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"log"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 )
 
 func main() {
+	//the config is read before the commands parse their flags
+	configPath, sets, rest := globalFlags(os.Args[1:])
+	lib.SetConfigPath(configPath)
+	lib.SetOverrides(sets)
+	if !lib.ConfigExists() && !lib.OnboardingDismissed() && wantsOnboarding(os.Args[1:], rest) {
+		configure.Onboard()
+	}
 	//load config
 	config, err := lib.LoadConfig()
 	if err != nil {
@@ -29,8 +43,60 @@ func main() {
 	}
 }
 
+// globalFlags finds --config and --set among the arguments of any command, and returns the
+// arguments that are not flags with them
+func globalFlags(args []string) (string, []string, []string) {
+	flags := pflag.NewFlagSet("global", pflag.ContinueOnError)
+	flags.ParseErrorsWhitelist.UnknownFlags = true
+	flags.Usage = func() {}
+	path := flags.String("config", "", "")
+	sets := flags.StringArray("set", nil, "")
+	//known so they do not take the command after them as their value
+	flags.BoolP("version", "v", false, "")
+	flags.BoolP("help", "h", false, "")
+	flags.BoolP("stay", "s", false, "")
+	flags.Parse(args)
+	return *path, *sets, flags.Args()
+}
+
+// commands that run fine without a config or set it up themselves, and flags that only print
+var (
+	noOnboarding      = []string{"config", "completion", "__complete", "__completeNoDesc", "init-shell", "help"}
+	noOnboardingFlags = []string{"-h", "--help", "-v", "--version"}
+)
+
+// wantsOnboarding tells whether to ask for the configuration before running, only when someone is at the terminal.
+// The command is the first of the arguments left once the global flags are taken out.
+func wantsOnboarding(args []string, rest []string) bool {
+	if len(rest) > 0 && isOneOf(noOnboarding, rest[0]) {
+		return false
+	}
+	for _, arg := range args {
+		if isOneOf(noOnboardingFlags, arg) {
+			return false
+		}
+	}
+	return isTerminal(os.Stdin) && isTerminal(os.Stdout)
+}
+
+func isOneOf(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
 func rootCmd(config lib.Config, version string) *cobra.Command {
-	var versionFlag bool
+	var versionFlag, stayFlag bool
+	var configPath string
+	var sets []string
 	//add root command
 	rootCmd := &cobra.Command{
 		Use:   "nau",
@@ -40,6 +106,9 @@ func rootCmd(config lib.Config, version string) *cobra.Command {
 				fmt.Println("nau version:", version)
 				return
 			}
+			if stayFlag {
+				config.Stay_open = true
+			}
 			root.Execute(config)
 		},
 	}
@@ -49,39 +118,63 @@ func rootCmd(config lib.Config, version string) *cobra.Command {
         openCmd(config),
         archiveCmd(config),
         showCmd(config),
+        reindexCmd(config),
+        tagCmd(config),
+        untagCmd(config),
+        pinCmd(config, true),
+        pinCmd(config, false),
+        pathCmd(config),
+        initShellCmd(),
         )
 	rootCmd.CompletionOptions.HiddenDefaultCmd = true
 	rootCmd.SetHelpCommand(&cobra.Command{
 		Use:    "no-help",
 		Hidden: true,
 	})
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Config file to use instead of NAU_CONFIG or $XDG_CONFIG_HOME/nau/config.toml")
+	rootCmd.PersistentFlags().StringArrayVar(&sets, "set", nil, "Override a config field for this run, as FIELD=value")
 	rootCmd.Flags().BoolVarP(&versionFlag, "version", "v", false, "Print the version number")
+	rootCmd.Flags().BoolVarP(&stayFlag, "stay", "s", false, "Stay in nau after opening or archiving a project")
 	return rootCmd
 
 }
 func configCmd() *cobra.Command {
+	var showOriginFlag bool
 	cmd := &cobra.Command{
-		Use:   "config [field] [value]",
+		Use:               "config [field] [value]",
+		ValidArgsFunction: completeFields,
 		Short: "Set or get configuration values",
 		Long: `Manage nau's configuration.
 
 If it is the first time using now start by running "nau config" to set all configuration parameters.
 You can also set them individually by running "nau config field value" or print current values with
-"nau config field". Configurations are stored at "~/.config/naurc"`,
+"nau config field". Configurations are stored at "$XDG_CONFIG_HOME/nau/config.toml", which
+is "~/.config/nau/config.toml" by default. Use another file with --config or NAU_CONFIG.
+
+Every field can be overridden for a single run with a NAU_<FIELD> environment variable, such as
+NAU_EDITOR, or with --set FIELD=value, which wins over the environment. --show-origin prints
+each value in use and where it comes from: default, file, env or flag.`,
 		Example: `  nau config                # Set up all configuration values in NAU
   nau config author           # Print current value of "author"
-  nau config author John Doe  # Set author field to "John Doe"`,
+  nau config author John Doe  # Set author field to "John Doe"
+  nau config --show-origin    # Print all values and where they come from`,
 		Run: func(cmd *cobra.Command, args []string) {
+			if showOriginFlag {
+				configure.ShowOrigin()
+				return
+			}
 			configure.Execute(args)
 		},
 	}
+	cmd.Flags().BoolVar(&showOriginFlag, "show-origin", false, "Print every value in use and where it comes from")
 	return cmd
 }
 
 func newCmd(config lib.Config) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "new [template]",
-		Short: "Create a new project from a template",
+		Use:               "new [template]",
+		ValidArgsFunction: completeTemplates(config),
+		Short:             "Create a new project from a template",
 		Long: `Choose a project template and create a new instance.
 
 Collapse an existing template from the "templates" folder. The user is prompted with necessary
@@ -103,46 +196,84 @@ such as enviroment initialization.`,
 }
 
 func openCmd(config lib.Config) *cobra.Command {
+	var tagFlag, withFlag string
+	var sessionFlag, trustFlag bool
 	cmd := &cobra.Command{
-		Use: "open [project]",
+		Use:               "open [project]",
+		ValidArgsFunction: completeProjects(config),
 		Long: `Open a project in your preferred application.
 
-This command opens the specified project. If no project is provided, it opens the default project.
-You can specify the project by providing its name as an argument.`,
-		Example: `  nau open myproject  # Open the project named "myproject"
-  nau open myproj     # Open the project that best matches "myproj"`,
+This command opens the specified project. The best fuzzy matches are ranked by how often and how
+recently each one was opened, and a list to choose from is shown when several match about as well.
+An exact code or folder name always wins. Without a project it opens the project opened most often lately.
+With --tag only projects with that tag are considered.
+
+Projects open with the first command set in the project's .nau.json "open", the OPEN_<TEMPLATE>
+config field of its template, the OPEN config field, or else the editor. --with picks a named
+launcher from the project's .nau.json "launchers" or the LAUNCHER_<NAME> config fields, and
+"editor" always runs the editor. Arguments can use {{.Path}}, {{.Name}}, {{.Code}}, {{.Lang}}
+and {{.Editor}}. Launchers in .nau.json come with the project, so they only run after --trust
+allows them, and again whenever they change.
+
+--session attaches to the tmux session of the project, named after its code, creating it first with
+the windows of the session of its template or the global session in the config file. Layouts are written as
+name:command;name:command, an empty command is a shell and @open runs the launcher. Without a
+layout the session has an editor window running the launcher and a shell window. Set
+MULTIPLEXER to zellij to use zellij sessions instead.`,
+		Example: `  nau open myproject          # Open the project named "myproject"
+  nau open myproj             # Open the project that best matches "myproj"
+  nau open --tag client api   # Open the project tagged "client" that best matches "api"
+  nau open                    # Open the project opened most often lately
+  nau open --with code api    # Open the project that best matches "api" with the "code" launcher
+  nau open --session api      # Attach to the session of the project that best matches "api"`,
 		Short: "Open a project",
 		Run: func(cmd *cobra.Command, args []string) {
 			if len(args) > 0 {
-				open.Execute(config, args[0])
+				open.Execute(config, args[0], tagFlag, withFlag, sessionFlag, trustFlag)
 			} else {
-				cmd.Help()
+				open.Execute(config, "", tagFlag, withFlag, sessionFlag, trustFlag)
 			}
 		},
 	}
+	cmd.Flags().StringVarP(&tagFlag, "tag", "t", "", "Only consider projects with this tag")
+	cmd.RegisterFlagCompletionFunc("tag", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return matching(allTags(config), toComplete), cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().StringVarP(&withFlag, "with", "w", "", "Open with a named launcher")
+	cmd.Flags().BoolVarP(&sessionFlag, "session", "S", false, "Open in a multiplexer session of the project")
+	cmd.Flags().BoolVar(&trustFlag, "trust", false, "Allow the launchers in the project's .nau.json to run")
+	cmd.RegisterFlagCompletionFunc("with", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return matching(config.LauncherNames(), toComplete), cobra.ShellCompDirectiveNoFileComp
+	})
 
 	return cmd
 }
 
 func archiveCmd(config lib.Config) *cobra.Command {
+	var yesFlag bool
 	cmd := &cobra.Command{
-		Use:   "archive [project]",
-		Short: "Archive a project and move it to the `archive` folder.",
+		Use:               "archive [project]",
+		ValidArgsFunction: completeProjects(config),
+		Short:             "Archive a project and move it to the `archive` folder.",
 		Long: `Archive a project and move it to the "archive" folder.
 
-The project considered is the best fuzzy match. Use "nau" for more control. Before .tar and .zip
+The project considered is the best fuzzy match, or the one chosen from a list when several match
+about as well. An exact code or folder name always wins. The chosen project is shown for confirmation
+unless --yes is given. Use "nau" for more control. Before .tar and .zip
 the command "make archive" is run on the directory. Define it in your project's file to enable extra
 features, such as deleting git and node dependedncies.`,
 		Example: `  nau archive myproject  # Archive the project named "myproject"
-  nau archive myproj     # Archive the project that best matches "myproj"`,
+  nau archive myproj     # Archive the project that best matches "myproj"
+  nau archive MPR --yes  # Archive the project with code "MPR" without asking`,
 		Run: func(cmd *cobra.Command, args []string) {
 			if len(args) > 0 {
-				archive.Execute(config, args[0])
+				archive.Execute(config, args[0], yesFlag)
 			} else {
 				cmd.Help()
 			}
 		},
 	}
+	cmd.Flags().BoolVarP(&yesFlag, "yes", "y", false, "Archive without asking for confirmation")
 
 	return cmd
 }
@@ -161,3 +292,201 @@ Currently under construction. Run with no arguments to view current configuratio
 
 	return cmd
 }
+
+func reindexCmd(config lib.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reindex",
+		Short: "Rebuild the index of projects",
+		Long: `Scan all project folders again and rebuild the index.
+
+NAU keeps an index of your projects in "$XDG_CACHE_HOME/nau" or "~/.cache/nau" so commands do not have to walk every folder.
+The index is rebuilt automatically when a project folder is added or removed and at least once an hour.
+Run this command to refresh it right away, for instance after editing files inside a project.`,
+		Example: `  nau reindex  # Rebuild the index of projects`,
+		Run: func(cmd *cobra.Command, args []string) {
+			reindex.Execute(config)
+		},
+	}
+
+	return cmd
+}
+
+func tagCmd(config lib.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "tag <project> <tag...>",
+		ValidArgsFunction: completeTags(config),
+		Short:             "Add tags to a project",
+		Long: `Add tags to the project that best matches the query.
+
+Tags are stored in the ".nau.json" file of the project. Filter the grid by tag with "tag:name"
+and open tagged projects with "nau open --tag name".`,
+		Example: `  nau tag myproj client web  # Tag the project that best matches "myproj" with "client" and "web"`,
+		Args:    cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			tag.Execute(config, args[0], args[1:])
+		},
+	}
+
+	return cmd
+}
+
+func untagCmd(config lib.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "untag <project> [tag...]",
+		ValidArgsFunction: completeTags(config),
+		Short:             "Remove tags from a project",
+		Long: `Remove tags from the project that best matches the query.
+
+Without tags all the tags of the project are removed.`,
+		Example: `  nau untag myproj client  # Remove the tag "client"
+  nau untag myproj         # Remove all tags`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			tag.Untag(config, args[0], args[1:])
+		},
+	}
+
+	return cmd
+}
+
+func pinCmd(config lib.Config, pinned bool) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "pin <project>",
+		ValidArgsFunction: completeProjects(config),
+		Short:             "Always show a project first in the grid",
+		Example:           `  nau pin myproj  # Pin the project that best matches "myproj"`,
+		Args:              cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			tag.Pin(config, args[0], pinned)
+		},
+	}
+	if !pinned {
+		cmd.Use = "unpin <project>"
+		cmd.Short = "Stop showing a project first in the grid"
+		cmd.Example = `  nau unpin myproj  # Unpin the project that best matches "myproj"`
+	}
+
+	return cmd
+}
+
+func pathCmd(config lib.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "path [project]",
+		Short: "Print the path of a project",
+		Long: `Print the path of the project that best matches the query.
+
+Projects are found like "nau open" finds them, without a project the one opened most often lately
+is printed. Use it to jump to projects from the shell, see "nau init-shell".`,
+		Example:           `  cd "$(nau path myproj)"  # Go to the project that best matches "myproj"`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeProjects(config),
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) > 0 {
+				shell.Path(config, args[0])
+			} else {
+				shell.Path(config, "")
+			}
+		},
+	}
+
+	return cmd
+}
+
+func initShellCmd() *cobra.Command {
+	var nameFlag string
+	cmd := &cobra.Command{
+		Use:   "init-shell <bash|zsh|fish>",
+		Short: "Print the shell function to jump to projects",
+		Long: `Print a shell function, named "ncd" unless --name is given, that changes directory to the
+project that best matches its argument. Add it to the configuration of your shell.`,
+		Example: `  eval "$(nau init-shell bash)"   # in ~/.bashrc
+  eval "$(nau init-shell zsh)"    # in ~/.zshrc
+  nau init-shell fish | source    # in ~/.config/fish/config.fish
+  eval "$(nau init-shell bash --name p)"  # Jump with "p <project>" instead`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: shell.Shells,
+		Run: func(cmd *cobra.Command, args []string) {
+			shell.Init(args[0], nameFlag)
+		},
+	}
+	cmd.Flags().StringVarP(&nameFlag, "name", "n", shell.DefaultFunction, "Name of the shell function")
+
+	return cmd
+}
+
+// values that start with what has been typed so far
+func matching(values []string, toComplete string) []string {
+	var matches []string
+	for _, value := range values {
+		if strings.HasPrefix(strings.ToLower(value), strings.ToLower(toComplete)) {
+			matches = append(matches, value)
+		}
+	}
+	return matches
+}
+
+func completeFields(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return matching(lib.CustomizableFields, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completes the first argument with the folder names of the projects, described by their names
+func completeProjects(config lib.Config) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		projects, _ := lib.GetProjects(config)
+		var completions []string
+		for _, project := range projects {
+			folder := filepath.Base(project.Path)
+			if strings.HasPrefix(strings.ToLower(folder), strings.ToLower(toComplete)) {
+				completions = append(completions, folder+"\t"+project.Display_Name)
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func completeTemplates(config lib.Config) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		templates := []string{"Empty"}
+		for template := range config.Templates {
+			templates = append(templates, template)
+		}
+		sort.Strings(templates)
+		return matching(templates, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completes a project and then the tags in use
+func completeTags(config lib.Config) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	projects := completeProjects(config)
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return projects(cmd, args, toComplete)
+		}
+		return matching(allTags(config), toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func allTags(config lib.Config) []string {
+	projects, _ := lib.GetProjects(config)
+	seen := make(map[string]bool)
+	var tags []string
+	for _, project := range projects {
+		for _, tag := range project.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}